@@ -0,0 +1,248 @@
+package fcm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SendN sends n copies of the message concurrently, useful for load testing.
+// Concurrency is limited by Config.MaxConcurrency.
+//
+// Returns names of all sent messages or the first error.
+func (c *Client) SendN(ctx context.Context, message *Message, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, errors.New("n must be positive")
+	}
+	if err := c.prepare(ctx, message); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var firstErr error
+	names := make([]string, n)
+
+	c.fanOut(ctx, n, func(i int) {
+		name, err := c.send(ctx, message, sendOptions{})
+		if err != nil {
+			once.Do(func() {
+				firstErr = err
+				cancel()
+			})
+			return
+		}
+		names[i] = name
+	})
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// fanOut calls fn for each index in [0, n) with limited concurrency.
+// Stops dispatching new calls when ctx is done.
+func (c *Client) fanOut(ctx context.Context, n int, fn func(i int)) {
+	sem := make(chan struct{}, min(n, c.maxWorkers))
+	var wg sync.WaitGroup
+
+	for i := range n {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+		// select picks randomly when both cases are ready, prefer cancellation.
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// TopicSendResult is a result of sending a message to a single topic in [Client.SendToTopics].
+type TopicSendResult struct {
+	Topic string
+	Name  string
+	Err   error
+}
+
+// SendToTopics sends the message to each of the topics, Token and Condition of the message are ignored.
+// When parallel is true sends run concurrently limited by Config.MaxConcurrency.
+//
+// All topics and messages are validated before sending, a validation error is returned immediately.
+// Send errors are reported per topic in [TopicSendResult].
+func (c *Client) SendToTopics(ctx context.Context, message *Message, topics []string, parallel bool) ([]*TopicSendResult, error) {
+	switch {
+	case message == nil:
+		return nil, errors.New("message must not be nil")
+	case len(topics) == 0:
+		return nil, errors.New("topics must not be empty")
+	}
+
+	msgs := make([]*Message, len(topics))
+	for i, topic := range topics {
+		if err := ValidateTopic(topic); err != nil {
+			return nil, fmt.Errorf("topic %q: %w", topic, err)
+		}
+
+		msg := *message
+		msg.Token, msg.Condition, msg.Topic = "", "", topic
+		if err := c.prepare(ctx, &msg); err != nil {
+			return nil, fmt.Errorf("topic %q: %w", topic, err)
+		}
+		msgs[i] = &msg
+	}
+
+	results := make([]*TopicSendResult, len(topics))
+	send := func(i int) {
+		name, err := c.send(ctx, msgs[i], sendOptions{})
+		results[i] = &TopicSendResult{Topic: topics[i], Name: name, Err: err}
+	}
+
+	if parallel {
+		c.fanOut(ctx, len(topics), send)
+	} else {
+		for i := range topics {
+			send(i)
+		}
+	}
+
+	// fanOut stops dispatching when ctx is done.
+	for i, r := range results {
+		if r == nil {
+			results[i] = &TopicSendResult{Topic: topics[i], Err: ctx.Err()}
+		}
+	}
+	return results, nil
+}
+
+// BatchSendResult is a result of sending a single message in [Client.SendBatch].
+type BatchSendResult struct {
+	// Token of the sent message, empty for messages sent to a topic or a condition.
+	Token string
+	Name  string
+	Err   error
+}
+
+// BatchSendResponse is a result of [Client.SendBatch], Results are in order of messages.
+type BatchSendResponse struct {
+	Results []*BatchSendResult
+}
+
+// SuccessCount returns number of successfully sent messages.
+func (r *BatchSendResponse) SuccessCount() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// FailureCount returns number of failed messages.
+func (r *BatchSendResponse) FailureCount() int {
+	return len(r.Results) - r.SuccessCount()
+}
+
+// Tokens returns tokens of all messages in order.
+func (r *BatchSendResponse) Tokens() []string {
+	return r.tokens(func(*BatchSendResult) bool { return true })
+}
+
+// FailedTokens returns tokens of failed messages.
+func (r *BatchSendResponse) FailedTokens() []string {
+	return r.tokens(func(res *BatchSendResult) bool { return res.Err != nil })
+}
+
+// ValidTokens returns tokens of successfully sent messages.
+// Tokens rejected with [ErrUnregistered] are never included and should be removed by the caller.
+func (r *BatchSendResponse) ValidTokens() []string {
+	return r.tokens(func(res *BatchSendResult) bool { return res.Err == nil })
+}
+
+func (r *BatchSendResponse) tokens(keep func(*BatchSendResult) bool) []string {
+	var tokens []string
+	for _, res := range r.Results {
+		if res.Token != "" && keep(res) {
+			tokens = append(tokens, res.Token)
+		}
+	}
+	return tokens
+}
+
+// SendBatch sends each message concurrently limited by Config.MaxConcurrency.
+// Results are in order of messages, including validation errors.
+//
+// Returns [*BatchError] along with the response when at least one message failed.
+func (c *Client) SendBatch(ctx context.Context, messages []*Message) (*BatchSendResponse, error) {
+	if len(messages) == 0 {
+		return nil, errors.New("messages must not be empty")
+	}
+
+	results := make([]*BatchSendResult, len(messages))
+	c.fanOut(ctx, len(messages), func(i int) {
+		name, err := c.Send(ctx, messages[i])
+		results[i] = &BatchSendResult{Token: messageToken(messages[i]), Name: name, Err: err}
+	})
+
+	var errs []error
+	for i, r := range results {
+		// fanOut stops dispatching when ctx is done.
+		if r == nil {
+			r = &BatchSendResult{Token: messageToken(messages[i]), Err: ctx.Err()}
+			results[i] = r
+		}
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+
+	resp := &BatchSendResponse{Results: results}
+	if len(errs) > 0 {
+		return resp, &BatchError{Total: len(messages), Errors: errs}
+	}
+	return resp, nil
+}
+
+func messageToken(message *Message) string {
+	if message == nil {
+		return ""
+	}
+	return message.Token
+}
+
+// SendMulticast sends the message to each of the tokens, see [Client.SendBatch].
+// Topic and Condition of the message are ignored, the message itself is not modified.
+func (c *Client) SendMulticast(ctx context.Context, message *Message, tokens []string) (*BatchSendResponse, error) {
+	if message == nil {
+		return nil, errors.New("message must not be nil")
+	}
+
+	msgs := make([]*Message, len(tokens))
+	for i, token := range tokens {
+		msg := *message
+		msg.Token, msg.Topic, msg.Condition = token, "", ""
+		msgs[i] = &msg
+	}
+	return c.SendBatch(ctx, msgs)
+}
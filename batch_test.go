@@ -0,0 +1,242 @@
+package fcm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendN(t *testing.T) {
+	var count atomic.Int64
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		n := count.Add(1)
+		return newResponse(http.StatusOK, fmt.Sprintf(`{"name":"%d"}`, n)), nil
+	})
+
+	names, err := client.SendN(context.Background(), &Message{Token: "token"}, 100)
+	mustOk(t, err)
+	mustEqual(t, len(names), 100)
+	mustEqual(t, count.Load(), int64(100))
+	for _, name := range names {
+		if name == "" {
+			t.Fatal("empty name")
+		}
+	}
+
+	_, err = client.SendN(context.Background(), &Message{Token: "token"}, 0)
+	mustFail(t, err)
+}
+
+func TestSendNError(t *testing.T) {
+	var count atomic.Int64
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		if count.Add(1) == 3 {
+			return newResponse(http.StatusInternalServerError, `{}`), nil
+		}
+		return newResponse(http.StatusOK, `{"name":"1"}`), nil
+	})
+
+	_, err := client.SendN(context.Background(), &Message{Token: "token"}, 10)
+	mustFail(t, err)
+}
+
+func TestSendWithCallback(t *testing.T) {
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, `{"name":"1"}`), nil
+	})
+
+	var wg sync.WaitGroup
+	results := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go client.SendWithCallback(context.Background(), &Message{Token: "token"}, func(name string, err error) {
+			defer wg.Done()
+			if name != "1" {
+				err = fmt.Errorf("unexpected name %q", name)
+			}
+			results <- err
+		})
+	}
+	wg.Wait()
+	close(results)
+
+	for err := range results {
+		mustOk(t, err)
+	}
+
+	var called bool
+	client.SendWithCallback(context.Background(), nil, func(name string, err error) {
+		called = true
+		mustFail(t, err)
+	})
+	mustEqual(t, called, true)
+}
+
+func TestSendToTopics(t *testing.T) {
+	var count atomic.Int64
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		var body struct {
+			Message *Message `json:"message"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		count.Add(1)
+		if body.Message.Topic == "fail" {
+			return newResponse(http.StatusInternalServerError, `{}`), nil
+		}
+		return newResponse(http.StatusOK, fmt.Sprintf(`{"name":%q}`, body.Message.Topic)), nil
+	})
+
+	msg := &Message{Token: "token", Notification: &Notification{Title: "Hello"}}
+	topics := []string{"news", "fail", "/topics/sport"}
+
+	for _, parallel := range []bool{false, true} {
+		count.Store(0)
+		results, err := client.SendToTopics(context.Background(), msg, topics, parallel)
+		mustOk(t, err)
+		mustEqual(t, count.Load(), int64(3))
+		mustEqual(t, len(results), 3)
+
+		for i, r := range results {
+			mustEqual(t, r.Topic, topics[i])
+			if r.Topic == "fail" {
+				mustFail(t, r.Err)
+				continue
+			}
+			mustOk(t, r.Err)
+			mustEqual(t, r.Name, strings.TrimPrefix(topics[i], "/topics/"))
+		}
+	}
+	mustEqual(t, msg.Token, "token")
+
+	count.Store(0)
+	_, err := client.SendToTopics(context.Background(), msg, []string{"news", "bad topic"}, true)
+	mustFail(t, err)
+	_, err = client.SendToTopics(context.Background(), msg, nil, true)
+	mustFail(t, err)
+	mustEqual(t, count.Load(), int64(0))
+}
+
+func TestSendBatch(t *testing.T) {
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		var body struct {
+			Message *Message `json:"message"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(body.Message.Token, "bad") {
+			return newResponse(http.StatusNotFound, `{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`), nil
+		}
+		return newResponse(http.StatusOK, fmt.Sprintf(`{"name":%q}`, body.Message.Token)), nil
+	})
+
+	ctx := context.Background()
+	msg := &Message{Notification: &Notification{Title: "Hello"}}
+
+	resp, err := client.SendMulticast(ctx, msg, []string{"t1", "t2"})
+	mustOk(t, err)
+	mustEqual(t, resp.Results, []*BatchSendResult{{Token: "t1", Name: "t1"}, {Token: "t2", Name: "t2"}})
+
+	resp, err = client.SendMulticast(ctx, msg, []string{"bad1", "t2", "bad3"})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("want *BatchError, got %v", err)
+	}
+	mustEqual(t, batchErr.Total, 3)
+	mustEqual(t, len(batchErr.Errors), 2)
+	mustEqual(t, errors.Is(err, ErrUnregistered), true)
+	mustEqual(t, batchErr.All(ErrUnregistered), true)
+	var fcmErr *FCMError
+	if !errors.As(err, &fcmErr) {
+		t.Fatalf("want *FCMError inside, got %v", err)
+	}
+	mustEqual(t, fcmErr.Code, ErrorCodeUnregistered)
+	mustEqual(t, resp.Results[1].Name, "t2")
+	mustEqual(t, errors.Is(resp.Results[0].Err, ErrUnregistered), true)
+	mustEqual(t, resp.SuccessCount(), 1)
+	mustEqual(t, resp.FailureCount(), 2)
+	mustEqual(t, resp.Tokens(), []string{"bad1", "t2", "bad3"})
+	mustEqual(t, resp.FailedTokens(), []string{"bad1", "bad3"})
+	mustEqual(t, resp.ValidTokens(), []string{"t2"})
+
+	resp, err = client.SendBatch(ctx, []*Message{{Token: "bad1"}, {Topic: "news", Token: "t1"}, {Topic: "news"}})
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("want *BatchError, got %v", err)
+	}
+	mustEqual(t, errors.Is(err, ErrUnregistered), true)
+	mustEqual(t, batchErr.All(ErrUnregistered), false)
+	mustEqual(t, resp.Tokens(), []string{"bad1", "t1"})
+	mustEqual(t, resp.SuccessCount(), 1)
+
+	_, err = client.SendBatch(ctx, nil)
+	mustFail(t, err)
+}
+
+func TestSendBatchMaxConcurrency(t *testing.T) {
+	const limit = 3
+	var inFlight, maxInFlight atomic.Int64
+	client, err := NewClient(Config{
+		Client: &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return newResponse(http.StatusOK, `{"name":"1"}`), nil
+		}},
+		ProjectID:      "test-project",
+		MaxConcurrency: limit,
+	})
+	mustOk(t, err)
+
+	tokens := make([]string, 50)
+	for i := range tokens {
+		tokens[i] = fmt.Sprintf("token-%d", i)
+	}
+	_, err = client.SendMulticast(context.Background(), &Message{}, tokens)
+	mustOk(t, err)
+	mustEqual(t, maxInFlight.Load(), int64(limit))
+
+	mustEqual(t, newTestClient(t, nil).maxWorkers, 50)
+
+	_, err = NewClient(Config{Client: &fakeClient{}, ProjectID: "test-project", MaxConcurrency: -1})
+	mustFail(t, err)
+}
+
+func TestSendBatchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var count atomic.Int64
+	client, err := NewClient(Config{
+		Client: &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+			if count.Add(1) == 1 {
+				cancel()
+			}
+			return newResponse(http.StatusOK, `{"name":"1"}`), nil
+		}},
+		ProjectID:      "test-project",
+		MaxConcurrency: 1,
+	})
+	mustOk(t, err)
+
+	resp, err := client.SendMulticast(ctx, &Message{}, []string{"t1", "t2", "t3", "t4"})
+	mustFail(t, err)
+	mustEqual(t, len(resp.Results), 4)
+	mustEqual(t, resp.Results[3].Token, "t4")
+	mustEqual(t, errors.Is(resp.Results[3].Err, context.Canceled), true)
+	if count.Load() >= 4 {
+		t.Fatalf("want dispatching to stop after cancel, got %d requests", count.Load())
+	}
+}
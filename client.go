@@ -8,24 +8,146 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultEndpoint    = "https://fcm.googleapis.com"
+	defaultAPIVersion  = "v1"
+	defaultMaxDataSize = 4096
+
+	// defaultAuthTimeout limits token requests, so a stalled token endpoint doesn't block sends forever.
+	defaultAuthTimeout = 30 * time.Second
+
+	// defaultMaxConcurrency is small enough to not trip FCM quota and large enough for I/O bound sends.
+	defaultMaxConcurrency = 50
 )
 
-const defaultEndpoint = "https://fcm.googleapis.com/v1"
+// projectIDPattern matches GCP project IDs: 6 to 30 lowercase letters, digits or hyphens,
+// starting with a letter and not ending with a hyphen.
+var projectIDPattern = regexp.MustCompile("^[a-z][a-z0-9-]{4,28}[a-z0-9]$")
 
 // Client for the Firebase Cloud Messaging (FCM) service.
 type Client struct {
-	httpClient httpClient
-	endpoint   string
-	project    string
-	version    string
+	httpClient  httpClient
+	tokenSource oauth2.TokenSource
+	endpoint    string
+	apiVersion  string
+	iidEndpoint string
+	project     string
+	version     string
+	strict      bool
+	tokenOnly   bool
+	maxDataSize int
+	maxWorkers  int
+	logger      *slog.Logger
+	decorate    func(ctx context.Context, message *Message)
+	limiter     RateLimiter
 }
 
 type Config struct {
-	Client      httpClient
+	Client httpClient
+
+	// Credentials is a JSON credentials file. Supported types are:
+	//   - service_account: key file from Firebase project settings,
+	//   - authorized_user: file created by gcloud CLI,
+	//   - external_account: workload identity federation (AWS, Azure, OIDC, file or URL sourced),
+	//   - external_account_authorized_user: workforce identity federation,
+	//   - impersonated_service_account: file created by gcloud CLI.
 	Credentials []byte
-	ProjectID   string
-	Endpoint    string
+
+	// CredentialsFile is a path to JSON credentials file, mutually exclusive with Credentials.
+	//
+	// Precedence of authorization is Client, SkipAuth, TokenSource, Credentials or CredentialsFile.
+	// When none of them is set Application Default Credentials are used,
+	// see https://cloud.google.com/docs/authentication/application-default-credentials.
+	CredentialsFile string
+
+	ProjectID string
+
+	// Endpoint is a base URL of FCM API without API version. Default is https://fcm.googleapis.com.
+	// Trailing API version like in https://fcm.googleapis.com/v1 is accepted for backward compatibility.
+	Endpoint string
+
+	// APIVersion is a version of FCM API used in request paths. Default is v1.
+	APIVersion string
+
+	// TokenSource is used instead of Credentials when set.
+	TokenSource oauth2.TokenSource
+
+	// UserAgent is sent with each request. Ignored when Client is set.
+	UserAgent string
+
+	// Timeout of each HTTP request. Ignored for sends when Client is set.
+	// OAuth token and impersonation requests use it too, for them default is 30 seconds.
+	Timeout time.Duration
+
+	// BaseTransport is wrapped by authorization and header transports, useful for a custom dialer or mTLS.
+	// Default is a clone of http.DefaultTransport. Ignored for sends when Client is set.
+	// OAuth token and impersonation requests use it too.
+	BaseTransport http.RoundTripper
+
+	// Proxy returns a proxy URL for a request, nil URL means no proxy.
+	// Default is http.ProxyFromEnvironment. Ignored when BaseTransport is set, see it for details.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// DisableHTTP2 forces HTTP/1.1, by default HTTP/2 is negotiated. Ignored when BaseTransport is set.
+	DisableHTTP2 bool
+
+	// ImpersonateServiceAccount is an email of the service account to impersonate.
+	// Credentials or TokenSource must have the Service Account Token Creator role on it.
+	ImpersonateServiceAccount string
+
+	// MessagingScopeOnly requests only firebase.messaging OAuth scope which is enough to send messages.
+	// By default the scopes of Firebase Admin SDK are requested.
+	MessagingScopeOnly bool
+
+	// SkipAuth disables authorization, so credentials are not required.
+	// Useful with Firebase Emulator Suite set via Endpoint.
+	SkipAuth bool
+
+	// RequireTokenTarget rejects messages sent to a topic or a condition.
+	// Safety guard for production to not notify all subscribers by mistake.
+	RequireTokenTarget bool
+
+	// MaxDataSize limits total size of keys and values in Message.Data and AndroidConfig.Data.
+	// Default is 4096 bytes as FCM limit.
+	MaxDataSize int
+
+	// Logger for sent and failed messages. Default is slog.Default().
+	Logger *slog.Logger
+
+	// MaxConcurrency limits number of concurrent requests in batch methods like [Client.SendBatch].
+	// Default is 50.
+	MaxConcurrency int
+
+	// StrictValidation enables additional checks before sending, see [Message.ValidateStrict].
+	StrictValidation bool
+
+	// MessageDecorator is invoked before validation of each sent message.
+	// Useful to stamp fields like FCMOptions.AnalyticsLabel from context values.
+	// The message is modified in place.
+	MessageDecorator func(ctx context.Context, message *Message)
+
+	// RateLimiter is waited before each send request when set, see [NewTokenBucketLimiter].
+	// It applies per Client instance and does not coordinate across processes.
+	RateLimiter RateLimiter
+
+	// RateLimit is a number of send requests per second, zero disables rate limiting.
+	// Shortcut for RateLimiter created with [NewTokenBucketLimiter] and Burst.
+	RateLimit rate.Limit
+
+	// Burst is a maximal number of requests sent at once under RateLimit. Default is 1.
+	Burst int
 }
 
 type httpClient interface {
@@ -35,61 +157,295 @@ type httpClient interface {
 // NewClient creates a new instance of the Firebase Cloud Messaging Client.
 func NewClient(cfg Config) (*Client, error) {
 	switch {
-	case len(cfg.Credentials) == 0:
-		return nil, errors.New("credentials not provided")
 	case cfg.ProjectID == "":
 		return nil, errors.New("project ID is required to access Firebase Cloud Messaging client")
+	case !projectIDPattern.MatchString(cfg.ProjectID):
+		return nil, fmt.Errorf("malformed project ID %q: want 6 to 30 lowercase letters, digits or hyphens starting with a letter", cfg.ProjectID)
+	case cfg.MaxDataSize < 0:
+		return nil, errors.New("max data size must not be negative")
+	case cfg.MaxConcurrency < 0:
+		return nil, errors.New("max concurrency must not be negative")
+	case cfg.RateLimit < 0:
+		return nil, errors.New("rate limit must not be negative")
+	case cfg.RateLimit > 0 && cfg.RateLimiter != nil:
+		return nil, errors.New("rate limit and rate limiter are mutually exclusive")
+	case cfg.CredentialsFile != "" && len(cfg.Credentials) != 0:
+		return nil, errors.New("credentials and credentials file are mutually exclusive")
 	}
 
-	if cfg.Client == nil {
-		trans, err := newHTTPClient(cfg.Credentials)
+	if cfg.RateLimit > 0 {
+		cfg.RateLimiter = NewTokenBucketLimiter(cfg.RateLimit, cfg.Burst)
+	}
+
+	scopes := firebaseScopes
+	if cfg.MessagingScopeOnly {
+		scopes = messagingScopes
+	}
+	// Impersonation requires cloud-platform scope of base credentials, only the target token is narrowed.
+	baseScopes := scopes
+	if cfg.ImpersonateServiceAccount != "" {
+		baseScopes = firebaseScopes
+	}
+
+	// Token requests share the base transport with FCM requests.
+	cfg.BaseTransport = configBaseTransport(cfg)
+	authClient := &http.Client{
+		Transport: cfg.BaseTransport,
+		Timeout:   cmp.Or(cfg.Timeout, defaultAuthTimeout),
+	}
+
+	if cfg.Client == nil && !cfg.SkipAuth && cfg.TokenSource == nil {
+		if cfg.CredentialsFile != "" {
+			creds, err := os.ReadFile(cfg.CredentialsFile)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read credentials file: %w", err)
+			}
+			cfg.Credentials = creds
+		}
+
+		var ts oauth2.TokenSource
+		var err error
+		if len(cfg.Credentials) != 0 {
+			ts, err = credentialsTokenSource(cfg.Credentials, baseScopes, authClient)
+		} else {
+			ts, err = defaultTokenSource(baseScopes, authClient)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("cannot create HTTP client: %w", err)
+			return nil, fmt.Errorf("cannot create token source: %w", err)
 		}
-		cfg.Client = trans
+		cfg.TokenSource = ts
+	}
+	if cfg.ImpersonateServiceAccount != "" {
+		if cfg.TokenSource == nil {
+			return nil, errors.New("credentials are required to impersonate service account")
+		}
+		cfg.TokenSource = newImpersonateTokenSource(cfg.TokenSource, cfg.ImpersonateServiceAccount, scopes, authClient)
+	}
+	if cfg.TokenSource != nil {
+		cfg.TokenSource = oauth2.ReuseTokenSource(nil, cfg.TokenSource)
 	}
 
-	sendEndpoint := cmp.Or(cfg.Endpoint, defaultEndpoint)
+	if cfg.Client == nil {
+		cfg.Client = newHTTPClient(cfg)
+	}
+
+	apiVersion := cmp.Or(cfg.APIVersion, defaultAPIVersion)
+	endpoint := strings.TrimSuffix(cmp.Or(cfg.Endpoint, defaultEndpoint), "/")
+	// Legacy endpoints end with /v1 whatever APIVersion is.
+	for _, version := range []string{apiVersion, defaultAPIVersion} {
+		if trimmed, ok := strings.CutSuffix(endpoint, "/"+version); ok {
+			endpoint = trimmed
+			break
+		}
+	}
+	if err := validateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
 
 	return &Client{
-		httpClient: cfg.Client,
-		endpoint:   fmt.Sprintf("%s/projects/%s/messages:send", sendEndpoint, cfg.ProjectID),
-		version:    "github.com/cristalhq/fcm",
+		httpClient:  cfg.Client,
+		tokenSource: cfg.TokenSource,
+		project:     cfg.ProjectID,
+		endpoint:    endpoint,
+		apiVersion:  apiVersion,
+		iidEndpoint: defaultIIDEndpoint,
+		version:     modulePath,
+		strict:      cfg.StrictValidation,
+		tokenOnly:   cfg.RequireTokenTarget,
+		maxDataSize: cmp.Or(cfg.MaxDataSize, defaultMaxDataSize),
+		maxWorkers:  cmp.Or(cfg.MaxConcurrency, defaultMaxConcurrency),
+		logger:      cmp.Or(cfg.Logger, slog.Default()),
+		decorate:    cfg.MessageDecorator,
+		limiter:     cfg.RateLimiter,
 	}, nil
 }
 
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	switch {
+	case err != nil:
+		return fmt.Errorf("malformed endpoint %q: %w", endpoint, err)
+	case u.Scheme != "http" && u.Scheme != "https":
+		return fmt.Errorf("endpoint %q must have http or https scheme", endpoint)
+	case u.Host == "":
+		return fmt.Errorf("endpoint %q must have a host", endpoint)
+	default:
+		return nil
+	}
+}
+
+// NewClientWithOptions creates a new instance of the Firebase Cloud Messaging Client configured with options.
+func NewClientWithOptions(projectID string, opts ...Option) (*Client, error) {
+	cfg := Config{ProjectID: projectID}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return NewClient(cfg)
+}
+
+// Token returns the current OAuth2 access token used to authorize requests.
+// Useful to inspect token expiry when debugging authorization errors, do not log the token itself.
+//
+// Returns an error when the client has no token source (custom Config.Client or Config.SkipAuth).
+// oauth2.TokenSource is not context-aware, so on ctx cancellation the fetch is abandoned, not aborted.
+func (c *Client) Token(ctx context.Context) (*oauth2.Token, error) {
+	if c.tokenSource == nil {
+		return nil, errors.New("token source is not configured")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		token *oauth2.Token
+		err   error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		token, err := c.tokenSource.Token()
+		ch <- result{token, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.token, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Warmup fetches an access token to verify credentials and opens a connection to FCM endpoint,
+// so the first send doesn't pay for them. Token is not fetched when the client has no token source.
+//
+// Calling Warmup is optional, sends work without it.
+func (c *Client) Warmup(ctx context.Context) error {
+	if c.tokenSource != nil {
+		if _, err := c.Token(ctx); err != nil {
+			return fmt.Errorf("cannot fetch token: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.endpoint, http.NoBody)
+	if err != nil {
+		return err
+	}
+	// Any response means the connection is established, status code doesn't matter.
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot connect to FCM endpoint: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
 // Send a [Message] to Firebase Cloud Messaging (FCM).
 //
 // The Message must specify exactly one of Token, Topic and Condition fields.
 // FCM will customize the message for each target platform based on the arguments specified in the [Message].
 func (c *Client) Send(ctx context.Context, message *Message) (string, error) {
-	if err := validateMessage(message); err != nil {
+	return c.SendWithOptions(ctx, message)
+}
+
+// SendWithOptions is like [Client.Send] but allows to tune the outgoing HTTP request.
+func (c *Client) SendWithOptions(ctx context.Context, message *Message, opts ...SendOption) (string, error) {
+	if err := c.prepare(ctx, message); err != nil {
 		return "", err
 	}
-	return c.send(ctx, message)
+
+	var so sendOptions
+	for _, opt := range opts {
+		opt(&so)
+	}
+	return c.send(ctx, message, so)
 }
 
-func (c *Client) send(ctx context.Context, message *Message) (string, error) {
-	msg := struct {
-		Message *Message `json:"message"`
-	}{
-		Message: message,
+// SendDryRun validates the message by FCM without delivering it.
+// Server-side validation errors are returned as [FCMError] with FieldViolations.
+func (c *Client) SendDryRun(ctx context.Context, message *Message) (string, error) {
+	if err := c.prepare(ctx, message); err != nil {
+		return "", err
 	}
+	return c.send(ctx, message, sendOptions{validateOnly: true})
+}
 
-	body, err := json.Marshal(msg)
+// SendWithCallback is like [Client.Send] but passes the result to cb.
+// The callback is invoked synchronously in the calling goroutine,
+// so it can signal a WaitGroup or write to a channel in worker pools.
+//
+// Panics in cb are not recovered.
+func (c *Client) SendWithCallback(ctx context.Context, message *Message, cb func(name string, err error)) {
+	cb(c.Send(ctx, message))
+}
+
+// prepare decorates and validates the message.
+func (c *Client) prepare(ctx context.Context, message *Message) error {
+	if c.decorate != nil && message != nil {
+		c.decorate(ctx, message)
+	}
+	return c.validate(message)
+}
+
+func (c *Client) validate(message *Message) error {
+	if c.tokenOnly && message != nil && message.IsBroadcast() {
+		return errors.New("topic and condition targets are not allowed, token is required")
+	}
+
+	validate := validateMessage
+	if c.strict {
+		validate = validateMessageStrict
+	}
+	if err := validate(message); err != nil {
+		return err
+	}
+	return validateDataSize(message, c.maxDataSize)
+}
+
+func (c *Client) send(ctx context.Context, message *Message, opts sendOptions) (string, error) {
+	start := time.Now()
+	name, err := c.doSend(ctx, message, opts)
+
+	attrs := []slog.Attr{
+		slog.String("project_id", c.project),
+		slog.String("message_token", truncate(message.Token, 8)),
+	}
 	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		c.logger.LogAttrs(ctx, slog.LevelError, "fcm: send failed", attrs...)
 		return "", err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewBuffer(body))
+	attrs = append(attrs, slog.String("name", name), slog.Duration("latency", time.Since(start)))
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "fcm: message sent", attrs...)
+	return name, nil
+}
+
+func (c *Client) doSend(ctx context.Context, message *Message, opts sendOptions) (string, error) {
+	// Don't marshal a large body for a request which is never sent.
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return "", fmt.Errorf("rate limiter: %w", err)
+		}
+	}
+
+	body, err := json.Marshal(sendRequest{ValidateOnly: opts.validateOnly, Message: message})
 	if err != nil {
 		return "", err
 	}
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.projectURL("messages:send"), bytes.NewBuffer(body))
+	if err != nil {
+		return "", err
+	}
+	opts.apply(req)
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("c.httpClient.Do: %w", err)
 	}
+	defer resp.Body.Close()
 
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -97,30 +453,64 @@ func (c *Client) send(ctx context.Context, message *Message) (string, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("code: %d, body: '%s", resp.StatusCode, string(b))
+		return "", newFCMError(resp, b)
 	}
 
-	var result fcmResponse
+	var result sendResponse
 	if err := json.Unmarshal(b, &result); err != nil {
-		var errResp fcmErrorResponse
-		if err := json.Unmarshal(b, &errResp); err != nil {
-			return "", fmt.Errorf("json.Unmarshal(b, &errResp): %w", err)
-		}
 		return "", fmt.Errorf("json.Unmarshal(b, &resp): %w", err)
 	}
 
 	return result.Name, nil
 }
 
-type fcmResponse struct {
+// sendRequest is a request body of messages:send method.
+type sendRequest struct {
+	ValidateOnly bool     `json:"validate_only,omitempty"`
+	Message      *Message `json:"message"`
+}
+
+// sendResponse is a response body of messages:send method.
+type sendResponse struct {
 	Name string `json:"name"`
 }
 
-type fcmErrorResponse struct {
-	Error struct {
-		Details []struct {
-			Type      string `json:"@type"`
-			ErrorCode string `json:"errorCode"`
+// projectURL returns URL of the project resource at path like messages:send.
+func (c *Client) projectURL(path string) string {
+	return fmt.Sprintf("%s/%s/projects/%s/%s", c.endpoint, c.apiVersion, c.project, path)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// SendOption to configure a single send request.
+type SendOption func(*sendOptions)
+
+type sendOptions struct {
+	headers      http.Header
+	validateOnly bool
+}
+
+// WithHeader sets an HTTP header on the outgoing request.
+// Authorization header cannot be overridden and is ignored.
+func WithHeader(key, value string) SendOption {
+	return func(so *sendOptions) {
+		if http.CanonicalHeaderKey(key) == "Authorization" {
+			return
 		}
-	} `json:"error"`
+		if so.headers == nil {
+			so.headers = make(http.Header)
+		}
+		so.headers.Set(key, value)
+	}
+}
+
+func (so sendOptions) apply(req *http.Request) {
+	for k, v := range so.headers {
+		req.Header[k] = v
+	}
 }
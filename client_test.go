@@ -0,0 +1,480 @@
+package fcm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestSendWithHeader(t *testing.T) {
+	var got http.Header
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		got = req.Header
+		return newResponse(http.StatusOK, `{"name":"projects/test-project/messages/1"}`), nil
+	})
+
+	msg := &Message{Token: "token"}
+	name, err := client.SendWithOptions(context.Background(), msg,
+		WithHeader("X-Trace-Id", "trace-123"),
+		WithHeader("Authorization", "Bearer evil"),
+	)
+	mustOk(t, err)
+	mustEqual(t, name, "projects/test-project/messages/1")
+	mustEqual(t, got.Get("X-Trace-Id"), "trace-123")
+	mustEqual(t, got.Get("Authorization"), "")
+}
+
+func TestNewClientWithOptions(t *testing.T) {
+	t.Run("http client and endpoint", func(t *testing.T) {
+		var gotURL string
+		client, err := NewClientWithOptions("test-project",
+			WithHTTPClient(&fakeClient{do: func(req *http.Request) (*http.Response, error) {
+				gotURL = req.URL.String()
+				return newResponse(http.StatusOK, `{"name":"1"}`), nil
+			}}),
+			WithEndpoint("https://fcm.example.com/v1"),
+		)
+		mustOk(t, err)
+
+		_, err = client.Send(context.Background(), &Message{Token: "token"})
+		mustOk(t, err)
+		mustEqual(t, gotURL, "https://fcm.example.com/v1/projects/test-project/messages:send")
+	})
+
+	t.Run("token source, user agent and timeout", func(t *testing.T) {
+		var gotAuth, gotUA string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAuth = r.Header.Get("Authorization")
+			gotUA = r.Header.Get("User-Agent")
+			io.WriteString(w, `{"name":"1"}`)
+		}))
+		defer srv.Close()
+
+		client, err := NewClientWithOptions("test-project",
+			WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "secret"})),
+			WithEndpoint(srv.URL),
+			WithUserAgent("my-app/1.0"),
+			WithTimeout(5*time.Second),
+		)
+		mustOk(t, err)
+		mustEqual(t, client.httpClient.(*http.Client).Timeout, 5*time.Second)
+
+		_, err = client.Send(context.Background(), &Message{Token: "token"})
+		mustOk(t, err)
+		mustEqual(t, gotAuth, "Bearer secret")
+		mustEqual(t, gotUA, "my-app/1.0")
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+		_, err := NewClientWithOptions("test-project")
+		mustFail(t, err)
+	})
+}
+
+func TestSendCanceledContext(t *testing.T) {
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		t.Fatal("request must not be sent")
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.Send(ctx, &Message{Token: "token"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want canceled, got %v", err)
+	}
+}
+
+func TestSendStrictValidation(t *testing.T) {
+	msg := &Message{
+		Token:        "token",
+		Data:         map[string]string{"k": "v"},
+		Notification: &Notification{Title: "Hello"},
+		Webpush:      &WebpushConfig{Data: map[string]string{"k": "w"}},
+	}
+
+	for _, strict := range []bool{false, true} {
+		client, err := NewClient(Config{
+			Client: &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+				return newResponse(http.StatusOK, `{"name":"1"}`), nil
+			}},
+			ProjectID:        "test-project",
+			StrictValidation: strict,
+		})
+		mustOk(t, err)
+
+		_, err = client.Send(context.Background(), msg)
+		mustEqual(t, err != nil, strict)
+	}
+}
+
+func TestNewClientEndpoint(t *testing.T) {
+	testCases := []struct {
+		endpoint string
+		wantURL  string
+		wantErr  bool
+	}{
+		{"", "https://fcm.googleapis.com/v1/projects/test-project/messages:send", false},
+		{"http://localhost:8080/v1", "http://localhost:8080/v1/projects/test-project/messages:send", false},
+		{"http://localhost:8080", "http://localhost:8080/v1/projects/test-project/messages:send", false},
+		{"http://localhost:8080/fcm/", "http://localhost:8080/fcm/v1/projects/test-project/messages:send", false},
+		{"fcm.googleapis.com/v1", "", true},
+		{"ftp://fcm.googleapis.com", "", true},
+		{"https://", "", true},
+		{"https://fcm googleapis.com", "", true},
+	}
+
+	for _, tc := range testCases {
+		client, err := NewClient(Config{
+			Client:    &fakeClient{},
+			ProjectID: "test-project",
+			Endpoint:  tc.endpoint,
+		})
+		if tc.wantErr {
+			mustFail(t, err)
+			continue
+		}
+		mustOk(t, err)
+		mustEqual(t, client.projectURL("messages:send"), tc.wantURL)
+	}
+}
+
+func TestNewClientAPIVersion(t *testing.T) {
+	var gotURL string
+	client, err := NewClient(Config{
+		Client: &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+			gotURL = req.URL.String()
+			return newResponse(http.StatusOK, `{"name":"1"}`), nil
+		}},
+		ProjectID:  "test-project",
+		APIVersion: "v2beta",
+	})
+	mustOk(t, err)
+
+	_, err = client.Send(context.Background(), &Message{Token: "token"})
+	mustOk(t, err)
+	mustEqual(t, gotURL, "https://fcm.googleapis.com/v2beta/projects/test-project/messages:send")
+
+	for _, endpoint := range []string{"https://fcm.googleapis.com/v1", "https://fcm.googleapis.com/v2beta/"} {
+		client, err = NewClient(Config{
+			Client:     &fakeClient{},
+			ProjectID:  "test-project",
+			Endpoint:   endpoint,
+			APIVersion: "v2beta",
+		})
+		mustOk(t, err)
+		mustEqual(t, client.projectURL("messages:send"), "https://fcm.googleapis.com/v2beta/projects/test-project/messages:send")
+	}
+}
+
+func TestNewClientProjectID(t *testing.T) {
+	testCases := []struct {
+		projectID string
+		wantErr   bool
+	}{
+		{"test-project", false},
+		{"example-android-app", false},
+		{"abc123", false},
+		{"a23456789012345678901234567890", false},
+		{"", true},
+		{"abc12", true},
+		{"a234567890123456789012345678901", true},
+		{"Test-Project", true},
+		{"1test-project", true},
+		{"test-project-", true},
+		{"test_project", true},
+	}
+
+	for _, tc := range testCases {
+		_, err := NewClient(Config{
+			Client:    &fakeClient{},
+			ProjectID: tc.projectID,
+		})
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%q: want error %v, got %v", tc.projectID, tc.wantErr, err)
+		}
+	}
+}
+
+func TestNewClientEmulator(t *testing.T) {
+	client, err := NewClient(Config{
+		ProjectID: "test-project",
+		Endpoint:  "http://localhost:9099",
+		SkipAuth:  true,
+	})
+	mustOk(t, err)
+	mustEqual(t, client.projectURL("messages:send"), "http://localhost:9099/v1/projects/test-project/messages:send")
+
+	var gotAuth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Values("Authorization")
+		io.WriteString(w, `{"name":"1"}`)
+	}))
+	defer srv.Close()
+
+	client, err = NewClient(Config{
+		ProjectID: "test-project",
+		Endpoint:  srv.URL,
+		SkipAuth:  true,
+	})
+	mustOk(t, err)
+
+	_, err = client.Send(context.Background(), &Message{Token: "token"})
+	mustOk(t, err)
+	mustEqual(t, len(gotAuth), 0)
+}
+
+func TestClientToken(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	ts := &fakeTokenSource{token: &oauth2.Token{AccessToken: "secret", Expiry: expiry}}
+
+	client, err := NewClient(Config{
+		ProjectID:   "test-project",
+		TokenSource: ts,
+	})
+	mustOk(t, err)
+
+	token, err := client.Token(context.Background())
+	mustOk(t, err)
+	mustEqual(t, token.AccessToken, "secret")
+	mustEqual(t, token.Expiry, expiry)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Token(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want canceled, got %v", err)
+	}
+
+	blocking := &fakeTokenSource{token: ts.token, wait: make(chan struct{})}
+	defer close(blocking.wait)
+	client, err = NewClient(Config{
+		ProjectID:   "test-project",
+		TokenSource: blocking,
+	})
+	mustOk(t, err)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.Token(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded, got %v", err)
+	}
+
+	client = newTestClient(t, nil)
+	_, err = client.Token(context.Background())
+	mustFail(t, err)
+}
+
+func TestClientWarmup(t *testing.T) {
+	var gotMethod, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotAuth = r.Method, r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	ts := &fakeTokenSource{err: errors.New("invalid_grant")}
+	client, err := NewClient(Config{
+		ProjectID:   "test-project",
+		TokenSource: ts,
+		Endpoint:    srv.URL,
+	})
+	mustOk(t, err)
+	mustFail(t, client.Warmup(context.Background()))
+	mustEqual(t, gotMethod, "")
+
+	ts.token, ts.err = &oauth2.Token{AccessToken: "secret"}, nil
+	mustOk(t, client.Warmup(context.Background()))
+	mustEqual(t, gotMethod, http.MethodHead)
+	mustEqual(t, gotAuth, "Bearer secret")
+
+	srv.Close()
+	mustFail(t, client.Warmup(context.Background()))
+}
+
+type fakeTokenSource struct {
+	token *oauth2.Token
+	err   error
+	wait  chan struct{} // blocks Token until closed, when set.
+}
+
+func (ts *fakeTokenSource) Token() (*oauth2.Token, error) {
+	if ts.wait != nil {
+		<-ts.wait
+	}
+	return ts.token, ts.err
+}
+
+func TestSendRequireTokenTarget(t *testing.T) {
+	client, err := NewClient(Config{
+		Client: &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+			return newResponse(http.StatusOK, `{"name":"1"}`), nil
+		}},
+		ProjectID:          "test-project",
+		RequireTokenTarget: true,
+	})
+	mustOk(t, err)
+
+	ctx := context.Background()
+	_, err = client.Send(ctx, &Message{Token: "token"})
+	mustOk(t, err)
+	_, err = client.Send(ctx, &Message{Topic: "all"})
+	mustFail(t, err)
+	_, err = client.Send(ctx, &Message{Condition: "'a' in topics"})
+	mustFail(t, err)
+}
+
+func TestSendMaxDataSize(t *testing.T) {
+	client, err := NewClient(Config{
+		Client: &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+			return newResponse(http.StatusOK, `{"name":"1"}`), nil
+		}},
+		ProjectID:   "test-project",
+		MaxDataSize: 10,
+	})
+	mustOk(t, err)
+
+	ctx := context.Background()
+	msg := &Message{
+		Token:   "token",
+		Data:    map[string]string{"a": "1234"},
+		Android: &AndroidConfig{Data: map[string]string{"b": "123"}},
+	}
+	_, err = client.Send(ctx, msg)
+	mustOk(t, err)
+
+	msg.Android.Data["c"] = "1"
+	_, err = client.Send(ctx, msg)
+	mustEqual(t, err.Error(), "data size 11 bytes exceeds limit of 10 bytes")
+
+	client = newTestClient(t, nil)
+	mustEqual(t, client.maxDataSize, 4096)
+
+	_, err = NewClient(Config{Client: &fakeClient{}, ProjectID: "test-project", MaxDataSize: -1})
+	mustFail(t, err)
+}
+
+func TestSendLogging(t *testing.T) {
+	var buf bytes.Buffer
+	fail := false
+	client, err := NewClient(Config{
+		Client: &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+			if fail {
+				return newResponse(http.StatusBadRequest, `{}`), nil
+			}
+			return newResponse(http.StatusOK, `{"name":"msg-1"}`), nil
+		}},
+		ProjectID: "test-project",
+		Logger:    slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	mustOk(t, err)
+
+	msg := &Message{Token: "0123456789abcdef"}
+	_, err = client.Send(context.Background(), msg)
+	mustOk(t, err)
+
+	logs := buf.String()
+	for _, want := range []string{"level=DEBUG", "project_id=test-project", "message_token=01234567 ", "name=msg-1", "latency="} {
+		if !strings.Contains(logs, want) {
+			t.Fatalf("want %q in logs: %s", want, logs)
+		}
+	}
+
+	buf.Reset()
+	fail = true
+	_, err = client.Send(context.Background(), msg)
+	mustFail(t, err)
+	if logs := buf.String(); !strings.Contains(logs, "level=ERROR") || strings.Contains(logs, "89abcdef") {
+		t.Fatalf("unexpected logs: %s", logs)
+	}
+}
+
+type fakeClient struct {
+	do func(req *http.Request) (*http.Response, error)
+}
+
+func (c *fakeClient) Do(req *http.Request) (*http.Response, error) {
+	return c.do(req)
+}
+
+func newTestClient(tb testing.TB, do func(req *http.Request) (*http.Response, error)) *Client {
+	tb.Helper()
+
+	client, err := NewClient(Config{
+		Client:    &fakeClient{do: do},
+		ProjectID: "test-project",
+		Logger:    slog.New(slog.DiscardHandler),
+	})
+	mustOk(tb, err)
+	return client
+}
+
+func newResponse(code int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func mustOk(tb testing.TB, err error) {
+	tb.Helper()
+	if err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func mustFail(tb testing.TB, err error) {
+	tb.Helper()
+	if err == nil {
+		tb.Fatal("want error, got nil")
+	}
+}
+
+func mustEqual[T any](tb testing.TB, have, want T) {
+	tb.Helper()
+	if !reflect.DeepEqual(have, want) {
+		tb.Fatalf("\nhave: %+v\nwant: %+v\n", have, want)
+	}
+}
+
+func TestSendMessageDecorator(t *testing.T) {
+	type tenantKey struct{}
+
+	var gotBody string
+	client, err := NewClient(Config{
+		Client: &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+			b, _ := io.ReadAll(req.Body)
+			gotBody = string(b)
+			return newResponse(http.StatusOK, `{"name":"1"}`), nil
+		}},
+		ProjectID: "test-project",
+		MessageDecorator: func(ctx context.Context, message *Message) {
+			if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+				message.FCMOptions = &FCMOptions{AnalyticsLabel: tenant}
+			}
+		},
+	})
+	mustOk(t, err)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "tenant-42")
+	_, err = client.Send(ctx, &Message{Token: "token"})
+	mustOk(t, err)
+	mustEqual(t, gotBody, `{"message":{"fcm_options":{"analytics_label":"tenant-42"},"token":"token"}}`)
+
+	ctx = context.WithValue(context.Background(), tenantKey{}, "bad label!")
+	_, err = client.Send(ctx, &Message{Token: "token"})
+	mustFail(t, err)
+}
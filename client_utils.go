@@ -1,65 +1,36 @@
+//go:build !nooauth
+
 package fcm
 
 import (
 	"context"
-	_ "embed"
-	"errors"
-	"maps"
+	"fmt"
 	"net/http"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
-func newHTTPClient(rawCreds []byte) (*http.Client, error) {
-	trans, err := newTransport(rawCreds)
+func credentialsTokenSource(rawCreds []byte, scopes []string, client *http.Client) (oauth2.TokenSource, error) {
+	creds, err := internalCreds(rawCreds, scopes, client)
 	if err != nil {
 		return nil, err
 	}
-	return &http.Client{Transport: trans}, nil
-}
-
-type parameterTransport struct {
-	userAgent     string
-	quotaProject  string
-	requestReason string
-
-	base http.RoundTripper
-}
-
-func (t *parameterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	rt := t.base
-	if rt == nil {
-		return nil, errors.New("transport: no Transport specified")
-	}
-
-	newReq := *req
-	newReq.Header = make(http.Header)
-	maps.Copy(newReq.Header, req.Header)
-
-	return rt.RoundTrip(&newReq)
+	return creds.TokenSource, nil
 }
 
-func newTransport(rawCreds []byte) (http.RoundTripper, error) {
-	paramTransport := &parameterTransport{
-		base: http.DefaultTransport.(*http.Transport).Clone(),
-	}
-	var trans http.RoundTripper = paramTransport
-
-	creds, err := internalCreds(rawCreds)
+// defaultTokenSource returns a token source from Application Default Credentials.
+func defaultTokenSource(scopes []string, client *http.Client) (oauth2.TokenSource, error) {
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+	creds, err := google.FindDefaultCredentials(ctx, scopes...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("credentials not provided and default credentials not found: %w", err)
 	}
-
-	trans = &oauth2.Transport{
-		Base:   trans,
-		Source: creds.TokenSource,
-	}
-	return trans, nil
+	return creds.TokenSource, nil
 }
 
-func internalCreds(rawCreds []byte) (*google.Credentials, error) {
-	return credentialsFromJSON(rawCreds)
+func internalCreds(rawCreds []byte, scopes []string, client *http.Client) (*google.Credentials, error) {
+	return credentialsFromJSON(rawCreds, scopes, client)
 }
 
 // credentialsFromJSON returns a google.Credentials from the JSON data
@@ -74,15 +45,15 @@ func internalCreds(rawCreds []byte) (*google.Credentials, error) {
 //
 // - Otherwise, executes standard OAuth 2.0 flow
 // More details: google.aip.dev/auth/4111
-func credentialsFromJSON(data []byte) (*google.Credentials, error) {
+func credentialsFromJSON(data []byte, scopes []string, client *http.Client) (*google.Credentials, error) {
 	ctx := context.Background()
 
 	var params google.CredentialsParams
-	params.Scopes = firebaseScopes
+	params.Scopes = scopes
 
-	oauth2Client := oauth2.NewClient(ctx, nil)
+	// Token requests use the client, so they go through the configured transport.
 	params.TokenURL = google.Endpoint.TokenURL
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, oauth2Client)
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
 
 	// By default, a standard OAuth 2.0 token source is created
 	cred, err := google.CredentialsFromJSONWithParams(ctx, data, params)
@@ -91,12 +62,3 @@ func credentialsFromJSON(data []byte) (*google.Credentials, error) {
 	}
 	return cred, nil
 }
-
-var firebaseScopes = []string{
-	"https://www.googleapis.com/auth/cloud-platform",
-	"https://www.googleapis.com/auth/datastore",
-	"https://www.googleapis.com/auth/devstorage.full_control",
-	"https://www.googleapis.com/auth/firebase",
-	"https://www.googleapis.com/auth/identitytoolkit",
-	"https://www.googleapis.com/auth/userinfo.email",
-}
@@ -0,0 +1,24 @@
+//go:build nooauth
+
+package fcm
+
+import (
+	"errors"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// credentialsTokenSource is a stub for builds without golang.org/x/oauth2/google.
+// Use Config.TokenSource or pass an authorized Config.Client instead.
+func credentialsTokenSource(rawCreds []byte, scopes []string, client *http.Client) (oauth2.TokenSource, error) {
+	if rawCreds != nil {
+		return nil, errors.New("credentials are not supported with nooauth build tag")
+	}
+	return nil, errors.New("credentials not provided")
+}
+
+// defaultTokenSource is a stub, Application Default Credentials are not supported with nooauth build tag.
+func defaultTokenSource(scopes []string, client *http.Client) (oauth2.TokenSource, error) {
+	return nil, errors.New("credentials not provided")
+}
@@ -0,0 +1,188 @@
+//go:build !nooauth
+
+package fcm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCredentialsExternalAccount(t *testing.T) {
+	var gotForm url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mustOk(t, r.ParseForm())
+		gotForm = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"access_token": "federated",
+			"issued_token_type": "urn:ietf:params:oauth:token-type:access_token",
+			"token_type": "Bearer",
+			"expires_in": 3600
+		}`)
+	}))
+	defer srv.Close()
+
+	subjectFile := filepath.Join(t.TempDir(), "token")
+	mustOk(t, os.WriteFile(subjectFile, []byte("subject-token"), 0o600))
+
+	rawCreds := fmt.Sprintf(`{
+		"type": "external_account",
+		"audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/provider",
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url": %q,
+		"credential_source": {"file": %q}
+	}`, srv.URL, subjectFile)
+
+	ts, err := credentialsTokenSource([]byte(rawCreds), firebaseScopes, http.DefaultClient)
+	mustOk(t, err)
+
+	token, err := ts.Token()
+	mustOk(t, err)
+	mustEqual(t, token.AccessToken, "federated")
+	mustEqual(t, gotForm.Get("subject_token"), "subject-token")
+	mustEqual(t, gotForm.Get("scope"), strings.Join(firebaseScopes, " "))
+}
+
+func TestCredentialsFile(t *testing.T) {
+	credsFile := filepath.Join(t.TempDir(), "creds.json")
+	rawCreds := `{
+		"type": "authorized_user",
+		"client_id": "client",
+		"client_secret": "secret",
+		"refresh_token": "refresh"
+	}`
+	mustOk(t, os.WriteFile(credsFile, []byte(rawCreds), 0o600))
+
+	client, err := NewClient(Config{ProjectID: "test-project", CredentialsFile: credsFile})
+	mustOk(t, err)
+	if client.tokenSource == nil {
+		t.Fatal("want token source")
+	}
+
+	_, err = NewClient(Config{ProjectID: "test-project", CredentialsFile: credsFile, Credentials: []byte(rawCreds)})
+	mustFail(t, err)
+
+	_, err = NewClient(Config{ProjectID: "test-project", CredentialsFile: filepath.Join(t.TempDir(), "missing.json")})
+	mustFail(t, err)
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", credsFile)
+	_, err = NewClientWithOptions("test-project")
+	mustOk(t, err)
+}
+
+func TestMessagingScopeOnly(t *testing.T) {
+	var gotScope string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mustOk(t, r.ParseForm())
+		gotScope = r.PostForm.Get("scope")
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"access_token": "token", "token_type": "Bearer", "expires_in": 3600}`)
+	}))
+	defer srv.Close()
+
+	subjectFile := filepath.Join(t.TempDir(), "token")
+	mustOk(t, os.WriteFile(subjectFile, []byte("subject-token"), 0o600))
+
+	rawCreds := fmt.Sprintf(`{
+		"type": "external_account",
+		"audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/provider",
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url": %q,
+		"credential_source": {"file": %q}
+	}`, srv.URL, subjectFile)
+
+	client, err := NewClient(Config{
+		ProjectID:          "test-project",
+		Credentials:        []byte(rawCreds),
+		MessagingScopeOnly: true,
+	})
+	mustOk(t, err)
+
+	_, err = client.Token(context.Background())
+	mustOk(t, err)
+	mustEqual(t, gotScope, "https://www.googleapis.com/auth/firebase.messaging")
+}
+
+func TestCredentialsBaseTransport(t *testing.T) {
+	rawCreds := `{
+		"type": "authorized_user",
+		"client_id": "client",
+		"client_secret": "secret",
+		"refresh_token": "refresh"
+	}`
+
+	var gotURL string
+	client, err := NewClient(Config{
+		ProjectID:   "test-project",
+		Credentials: []byte(rawCreds),
+		BaseTransport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			gotURL = req.URL.String()
+			resp := newResponse(http.StatusOK, `{"access_token": "token", "token_type": "Bearer", "expires_in": 3600}`)
+			resp.Header.Set("Content-Type", "application/json")
+			return resp, nil
+		}),
+	})
+	mustOk(t, err)
+
+	token, err := client.Token(context.Background())
+	mustOk(t, err)
+	mustEqual(t, token.AccessToken, "token")
+	mustEqual(t, gotURL, "https://oauth2.googleapis.com/token")
+}
+
+func TestMessagingScopeOnlyImpersonate(t *testing.T) {
+	var gotBaseScope string
+	var gotTarget struct {
+		Scope []string `json:"scope"`
+	}
+
+	subjectFile := filepath.Join(t.TempDir(), "token")
+	mustOk(t, os.WriteFile(subjectFile, []byte("subject-token"), 0o600))
+
+	rawCreds := fmt.Sprintf(`{
+		"type": "external_account",
+		"audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/provider",
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url": "https://sts.googleapis.com/v1/token",
+		"credential_source": {"file": %q}
+	}`, subjectFile)
+
+	client, err := NewClient(Config{
+		ProjectID:                 "test-project",
+		Credentials:               []byte(rawCreds),
+		ImpersonateServiceAccount: "sender@test-project.iam.gserviceaccount.com",
+		MessagingScopeOnly:        true,
+		BaseTransport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "iamcredentials.googleapis.com" {
+				mustOk(t, json.NewDecoder(req.Body).Decode(&gotTarget))
+				return newResponse(http.StatusOK, `{"accessToken":"impersonated","expireTime":"2030-01-02T15:04:05Z"}`), nil
+			}
+			mustOk(t, req.ParseForm())
+			gotBaseScope = req.PostForm.Get("scope")
+			resp := newResponse(http.StatusOK, `{
+				"access_token": "base",
+				"issued_token_type": "urn:ietf:params:oauth:token-type:access_token",
+				"token_type": "Bearer",
+				"expires_in": 3600
+			}`)
+			resp.Header.Set("Content-Type", "application/json")
+			return resp, nil
+		}),
+	})
+	mustOk(t, err)
+
+	token, err := client.Token(context.Background())
+	mustOk(t, err)
+	mustEqual(t, token.AccessToken, "impersonated")
+	mustEqual(t, gotTarget.Scope, messagingScopes)
+	mustEqual(t, gotBaseScope, strings.Join(firebaseScopes, " "))
+}
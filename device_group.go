@@ -0,0 +1,42 @@
+package fcm
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// DeviceGroupKey is a notification key of a device group.
+// It's created by FCM and starts with "APA91", unlike a registration token
+// it has no "<instance id>:" prefix.
+//
+// See https://firebase.google.com/docs/cloud-messaging/android/topic-messaging#device_group_messaging
+type DeviceGroupKey = string
+
+// SendToDeviceGroup sends the message to all devices of the group.
+// Token of the message is set to the notification key, the message itself is not modified.
+func (c *Client) SendToDeviceGroup(ctx context.Context, notificationKey DeviceGroupKey, message *Message) (string, error) {
+	if err := validateDeviceGroupKey(notificationKey); err != nil {
+		return "", err
+	}
+	if message == nil {
+		return "", errors.New("message must not be nil")
+	}
+
+	msg := *message
+	msg.Token = notificationKey
+	return c.Send(ctx, &msg)
+}
+
+func validateDeviceGroupKey(key DeviceGroupKey) error {
+	switch {
+	case key == "":
+		return errors.New("notification key must not be empty")
+	case strings.Contains(key, ":"):
+		return errors.New("notification key looks like a registration token")
+	case !strings.HasPrefix(key, "APA91"):
+		return errors.New("notification key must start with APA91")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+package fcm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSendToDeviceGroup(t *testing.T) {
+	var gotToken string
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		var body struct {
+			Message *Message `json:"message"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		gotToken = body.Message.Token
+		return newResponse(http.StatusOK, `{"name":"1"}`), nil
+	})
+
+	ctx := context.Background()
+	msg := &Message{Notification: &Notification{Title: "Hello"}}
+	name, err := client.SendToDeviceGroup(ctx, "APA91bGHXQBB-key", msg)
+	mustOk(t, err)
+	mustEqual(t, name, "1")
+	mustEqual(t, gotToken, "APA91bGHXQBB-key")
+	mustEqual(t, msg.Token, "")
+
+	for _, key := range []string{"", "fXy1:APA91bGHXQBB-token", "not-a-key"} {
+		_, err = client.SendToDeviceGroup(ctx, key, msg)
+		mustFail(t, err)
+	}
+
+	_, err = client.SendToDeviceGroup(ctx, "APA91bGHXQBB-key", &Message{Topic: "news"})
+	mustFail(t, err)
+}
@@ -0,0 +1,192 @@
+package fcm
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Error codes returned by FCM.
+//
+// See https://firebase.google.com/docs/reference/fcm/rest/v1/ErrorCode
+const (
+	ErrorCodeUnspecified         = "UNSPECIFIED_ERROR"
+	ErrorCodeInvalidArgument     = "INVALID_ARGUMENT"
+	ErrorCodeUnregistered        = "UNREGISTERED"
+	ErrorCodeSenderIDMismatch    = "SENDER_ID_MISMATCH"
+	ErrorCodeQuotaExceeded       = "QUOTA_EXCEEDED"
+	ErrorCodeUnavailable         = "UNAVAILABLE"
+	ErrorCodeInternal            = "INTERNAL"
+	ErrorCodeThirdPartyAuthError = "THIRD_PARTY_AUTH_ERROR"
+)
+
+// Sentinel errors matching [FCMError] with the corresponding code via [errors.Is].
+var (
+	ErrInvalidArgument     = errors.New("fcm: invalid argument")
+	ErrUnregistered        = errors.New("fcm: unregistered")
+	ErrSenderIDMismatch    = errors.New("fcm: sender id mismatch")
+	ErrQuotaExceeded       = errors.New("fcm: quota exceeded")
+	ErrUnavailable         = errors.New("fcm: unavailable")
+	ErrInternal            = errors.New("fcm: internal")
+	ErrThirdPartyAuthError = errors.New("fcm: third party auth error")
+)
+
+var codeErrors = map[string]error{
+	ErrorCodeInvalidArgument:     ErrInvalidArgument,
+	ErrorCodeUnregistered:        ErrUnregistered,
+	ErrorCodeSenderIDMismatch:    ErrSenderIDMismatch,
+	ErrorCodeQuotaExceeded:       ErrQuotaExceeded,
+	ErrorCodeUnavailable:         ErrUnavailable,
+	ErrorCodeInternal:            ErrInternal,
+	ErrorCodeThirdPartyAuthError: ErrThirdPartyAuthError,
+}
+
+// FCMError is returned when FCM responds with a non-successful status code.
+//
+// See https://firebase.google.com/docs/reference/fcm/rest/v1/ErrorCode
+type FCMError struct {
+	StatusCode int    // HTTP status code.
+	Code       string // FCM error code (like UNREGISTERED) or status (like INVALID_ARGUMENT).
+	Message    string
+
+	// RetryAfterSeconds is taken from Retry-After header, zero when absent.
+	RetryAfterSeconds int
+
+	// FieldViolations describe invalid fields of the message, usually with INVALID_ARGUMENT code.
+	FieldViolations []FieldViolation
+
+	// RawBody is the verbatim response body truncated to 4KB, useful for logging and support tickets.
+	RawBody []byte
+}
+
+func (e *FCMError) Error() string {
+	return fmt.Sprintf("code: %d, error: %s, message: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is reports whether target is the sentinel error of the code, like [ErrUnregistered].
+func (e *FCMError) Is(target error) bool {
+	sentinel, ok := codeErrors[e.Code]
+	return ok && sentinel == target
+}
+
+// IsUnregistered reports whether the token is no longer valid (UNREGISTERED).
+// Not retriable, the token should be removed.
+func (e *FCMError) IsUnregistered() bool {
+	return e.Code == ErrorCodeUnregistered
+}
+
+// IsInvalidArgument reports whether the request was malformed (INVALID_ARGUMENT).
+// Not retriable, the message or the token must be fixed.
+func (e *FCMError) IsInvalidArgument() bool {
+	return e.Code == ErrorCodeInvalidArgument
+}
+
+// IsInternal reports whether FCM failed with an unknown internal error (INTERNAL).
+// Retriable with exponential backoff.
+func (e *FCMError) IsInternal() bool {
+	return e.Code == ErrorCodeInternal
+}
+
+// IsQuotaExceeded reports whether a sending limit was exceeded (QUOTA_EXCEEDED).
+// Retriable after RetryAfterSeconds or with exponential backoff.
+func (e *FCMError) IsQuotaExceeded() bool {
+	return e.Code == ErrorCodeQuotaExceeded
+}
+
+// FieldViolation is an invalid field reported by FCM.
+type FieldViolation struct {
+	Field       string `json:"field"` // path like message.token.
+	Description string `json:"description"`
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			Type            string           `json:"@type"`
+			ErrorCode       string           `json:"errorCode"`
+			FieldViolations []FieldViolation `json:"fieldViolations"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// maxRawBodySize limits FCMError.RawBody to not keep huge responses like HTML error pages.
+const maxRawBodySize = 4096
+
+// maxErrorMessageSize limits FCMError.Message taken from a body which isn't a JSON error.
+const maxErrorMessageSize = 256
+
+func newFCMError(resp *http.Response, body []byte) *FCMError {
+	fcmErr := &FCMError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.ToValidUTF8(truncate(string(body), maxErrorMessageSize), ""),
+		RawBody:    bytes.Clone(body[:min(len(body), maxRawBodySize)]),
+	}
+
+	var errResp fcmErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		if errResp.Error.Message != "" {
+			fcmErr.Message = errResp.Error.Message
+		}
+		var errorCode string
+		for _, d := range errResp.Error.Details {
+			errorCode = cmp.Or(errorCode, d.ErrorCode)
+			fcmErr.FieldViolations = append(fcmErr.FieldViolations, d.FieldViolations...)
+		}
+		fcmErr.Code = cmp.Or(errorCode, errResp.Error.Status)
+	}
+
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		if secs, err := strconv.Atoi(after); err == nil && secs > 0 {
+			fcmErr.RetryAfterSeconds = secs
+		}
+	}
+	return fcmErr
+}
+
+// BatchError summarizes failures of a batch send like [Client.SendBatch].
+//
+// errors.Is and errors.As look into each failure, so errors.Is reports true for a sentinel
+// like [ErrUnregistered] when any failure matches it, see [BatchError.All] to check all of them.
+type BatchError struct {
+	Total  int     // number of messages in the batch.
+	Errors []error // failures only, in order of messages.
+}
+
+func (e *BatchError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("fcm: 0 of %d messages failed", e.Total)
+	}
+	return fmt.Sprintf("fcm: %d of %d messages failed, first error: %v", len(e.Errors), e.Total, e.Errors[0])
+}
+
+// Unwrap returns failures, so errors.Is and errors.As can reach them.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// All reports whether every failure matches target like errors.Is.
+func (e *BatchError) All(target error) bool {
+	if len(e.Errors) == 0 {
+		return false
+	}
+	for _, err := range e.Errors {
+		if !errors.Is(err, target) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package fcm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFCMErrorRetryAfter(t *testing.T) {
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		resp := newResponse(http.StatusTooManyRequests, `{
+			"error": {
+				"code": 429,
+				"message": "Quota exceeded.",
+				"status": "RESOURCE_EXHAUSTED",
+				"details": [{
+					"@type": "type.googleapis.com/google.firebase.fcm.v1.FcmError",
+					"errorCode": "QUOTA_EXCEEDED"
+				}]
+			}
+		}`)
+		resp.Header.Set("Retry-After", "30")
+		return resp, nil
+	})
+
+	_, err := client.Send(context.Background(), &Message{Token: "token"})
+
+	var fcmErr *FCMError
+	if !errors.As(err, &fcmErr) {
+		t.Fatalf("want *FCMError, got %T", err)
+	}
+	mustEqual(t, fcmErr.StatusCode, http.StatusTooManyRequests)
+	mustEqual(t, fcmErr.Code, "QUOTA_EXCEEDED")
+	mustEqual(t, fcmErr.Message, "Quota exceeded.")
+	mustEqual(t, fcmErr.RetryAfterSeconds, 30)
+}
+
+func TestFCMErrorCodes(t *testing.T) {
+	testCases := []struct {
+		code string
+		is   func(*FCMError) bool
+	}{
+		{ErrorCodeUnregistered, (*FCMError).IsUnregistered},
+		{ErrorCodeInvalidArgument, (*FCMError).IsInvalidArgument},
+		{ErrorCodeInternal, (*FCMError).IsInternal},
+		{ErrorCodeQuotaExceeded, (*FCMError).IsQuotaExceeded},
+	}
+
+	for _, tc := range testCases {
+		mustEqual(t, tc.is(&FCMError{Code: tc.code}), true)
+		mustEqual(t, tc.is(&FCMError{Code: ErrorCodeUnavailable}), false)
+	}
+}
+
+func TestFCMErrorRawBody(t *testing.T) {
+	const body = `{"error":{"code":400,"message":"Invalid value at 'message.token'","status":"INVALID_ARGUMENT"}}`
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusBadRequest, body), nil
+	})
+
+	_, err := client.Send(context.Background(), &Message{Token: "token"})
+
+	var fcmErr *FCMError
+	if !errors.As(err, &fcmErr) {
+		t.Fatalf("want *FCMError, got %T", err)
+	}
+	mustEqual(t, fcmErr.Code, ErrorCodeInvalidArgument)
+	mustEqual(t, string(fcmErr.RawBody), body)
+
+	large := newFCMError(newResponse(http.StatusBadGateway, ""), make([]byte, 2*maxRawBodySize))
+	mustEqual(t, len(large.RawBody), maxRawBodySize)
+	mustEqual(t, len(large.Message), maxErrorMessageSize)
+
+	html := "<html>x" + strings.Repeat("é", maxErrorMessageSize) + "</html>"
+	page := newFCMError(newResponse(http.StatusBadGateway, ""), []byte(html))
+	mustEqual(t, len(page.Message) <= maxErrorMessageSize, true)
+	mustEqual(t, strings.HasPrefix(html, page.Message), true)
+}
+
+func TestSendDryRunFieldViolations(t *testing.T) {
+	var gotBody string
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		gotBody = string(b)
+		return newResponse(http.StatusBadRequest, `{
+			"error": {
+				"code": 400,
+				"message": "Invalid value at 'message.android.ttl'",
+				"status": "INVALID_ARGUMENT",
+				"details": [{
+					"@type": "type.googleapis.com/google.rpc.BadRequest",
+					"fieldViolations": [{
+						"field": "message.android.ttl",
+						"description": "Invalid value at 'message.android.ttl'"
+					}]
+				}]
+			}
+		}`), nil
+	})
+
+	_, err := client.SendDryRun(context.Background(), &Message{Token: "token"})
+	mustEqual(t, gotBody, `{"validate_only":true,"message":{"token":"token"}}`)
+
+	var fcmErr *FCMError
+	if !errors.As(err, &fcmErr) {
+		t.Fatalf("want *FCMError, got %T", err)
+	}
+	mustEqual(t, fcmErr.IsInvalidArgument(), true)
+	mustEqual(t, fcmErr.FieldViolations, []FieldViolation{{
+		Field:       "message.android.ttl",
+		Description: "Invalid value at 'message.android.ttl'",
+	}})
+}
+
+func TestFCMErrorMultipleFieldViolations(t *testing.T) {
+	resp := newResponse(http.StatusBadRequest, "")
+	fcmErr := newFCMError(resp, []byte(`{
+		"error": {
+			"code": 400,
+			"message": "Request contains an invalid argument.",
+			"status": "INVALID_ARGUMENT",
+			"details": [
+				{
+					"@type": "type.googleapis.com/google.firebase.fcm.v1.FcmError",
+					"errorCode": "INVALID_ARGUMENT"
+				},
+				{
+					"@type": "type.googleapis.com/google.rpc.BadRequest",
+					"fieldViolations": [
+						{"field": "message.token", "description": "Invalid registration token"},
+						{"field": "message.android.notification.color", "description": "Invalid color format"}
+					]
+				}
+			]
+		}
+	}`))
+
+	mustEqual(t, fcmErr.Code, ErrorCodeInvalidArgument)
+	mustEqual(t, fcmErr.Message, "Request contains an invalid argument.")
+	mustEqual(t, fcmErr.FieldViolations, []FieldViolation{
+		{Field: "message.token", Description: "Invalid registration token"},
+		{Field: "message.android.notification.color", Description: "Invalid color format"},
+	})
+}
+
+func TestFCMErrorIs(t *testing.T) {
+	var err error = &FCMError{StatusCode: http.StatusNotFound, Code: ErrorCodeUnregistered}
+	mustEqual(t, errors.Is(err, ErrUnregistered), true)
+	mustEqual(t, errors.Is(err, ErrInvalidArgument), false)
+
+	err = fmt.Errorf("send: %w", &FCMError{Code: ErrorCodeQuotaExceeded})
+	mustEqual(t, errors.Is(err, ErrQuotaExceeded), true)
+
+	err = &FCMError{Code: "NOT_FOUND"}
+	mustEqual(t, errors.Is(err, ErrUnregistered), false)
+}
@@ -3,6 +3,8 @@ package fcm_test
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
+	"fmt"
 
 	"github.com/cristalhq/fcm"
 )
@@ -42,3 +44,54 @@ func Example() {
 
 	_ = pushID // notification ID
 }
+
+func ExampleApsAlert_webClip() {
+	// Web clips added to the iOS home screen have no app bundle,
+	// so the launch image is set by URL instead of a bundle file name.
+	msg := &fcm.Message{
+		Token: "...",
+		APNS: &fcm.APNSConfig{
+			Payload: &fcm.APNSPayload{
+				Aps: &fcm.Aps{
+					Alert: &fcm.ApsAlert{
+						Title:          "Order shipped",
+						Body:           "Your order is on its way",
+						LaunchImageURL: "https://example.com/launch.png",
+					},
+				},
+			},
+		},
+	}
+
+	b, err := json.Marshal(msg.APNS.Payload)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(b))
+
+	// Output:
+	// {"aps":{"alert":{"title":"Order shipped","body":"Your order is on its way","launch-image-url":"https://example.com/launch.png"}}}
+}
+
+func ExampleNewCriticalSound() {
+	sound, err := fcm.NewCriticalSound("alarm.aiff", 0.8, true)
+	if err != nil {
+		panic(err)
+	}
+
+	payload := &fcm.APNSPayload{
+		Aps: &fcm.Aps{
+			AlertString:   "Smoke detected",
+			CriticalSound: sound,
+		},
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(b))
+
+	// Output:
+	// {"aps":{"alert":"Smoke detected","sound":{"critical":1,"name":"alarm.aiff","volume":0.8}}}
+}
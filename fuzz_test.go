@@ -0,0 +1,89 @@
+package fcm
+
+import (
+	"testing"
+	"time"
+)
+
+func FuzzDurationRoundTrip(f *testing.F) {
+	for _, d := range []time.Duration{
+		0,
+		time.Second,
+		1500 * time.Millisecond,
+		time.Second + time.Nanosecond,
+		86400 * time.Second,
+	} {
+		f.Add(int64(d))
+	}
+
+	f.Fuzz(func(t *testing.T, n int64) {
+		d := time.Duration(n)
+		s := durationToString(d)
+		got, err := stringToDuration(s)
+		if err != nil {
+			t.Fatalf("%v: cannot parse %q: %v", d, s, err)
+		}
+		if got != d {
+			t.Fatalf("%v: %q parsed as %v", d, s, got)
+		}
+	})
+}
+
+func FuzzStringToDurationRoundTrip(f *testing.F) {
+	for _, s := range []string{
+		"0s",
+		"1s",
+		"1.5s",
+		"1.500000000s",
+		"1.000000001s",
+		"3.000000000s",
+		"86400s",
+		"-0.5s",
+	} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := stringToDuration(s)
+		if err != nil {
+			return
+		}
+
+		// Re-serialized string is canonical, it must survive another round trip unchanged.
+		canonical := durationToString(d)
+		got, err := stringToDuration(canonical)
+		if err != nil {
+			t.Fatalf("%q: cannot parse %q: %v", s, canonical, err)
+		}
+		if got != d {
+			t.Fatalf("%q: %q parsed as %v, want %v", s, canonical, got, d)
+		}
+		if again := durationToString(got); again != canonical {
+			t.Fatalf("%q: re-serialized as %q, want %q", s, again, canonical)
+		}
+	})
+}
+
+func FuzzParseMessage(f *testing.F) {
+	for _, s := range []string{
+		`{"token":"t"}`,
+		`{"token":"t","android":{"ttl":"3.5s","notification":{"vibrate_timings":["0.5s"]}}}`,
+		`{"token":"t","android":{"notification":{"light_settings":{"light_on_duration":"1s","light_off_duration":"1s"}}}}`,
+		`{"token":"t","android":{"notification":{"light_settings":{"color":{"red":1},"light_on_duration":"1s","light_off_duration":"1s"}}}}`,
+		`{"topic":"news","apns":{"payload":{"aps":{"alert":"Hello","badge":"3"}}}}`,
+		`{"condition":"'a' in topics","webpush":{"notification":{"title":"Hello"}}}`,
+	} {
+		f.Add([]byte(s))
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		// Inbound JSON must be rejected with an error, never panic.
+		msg, err := ParseMessage(b)
+		if err != nil {
+			return
+		}
+		if _, err := MarshalMessage(msg); err != nil {
+			t.Fatalf("%s: cannot marshal parsed message: %v", b, err)
+		}
+	})
+}
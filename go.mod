@@ -2,6 +2,9 @@ module github.com/cristalhq/fcm
 
 go 1.24.0
 
-require golang.org/x/oauth2 v0.34.0
+require (
+	golang.org/x/oauth2 v0.34.0
+	golang.org/x/time v0.14.0
+)
 
 require cloud.google.com/go/compute/metadata v0.3.0 // indirect
@@ -0,0 +1,187 @@
+package fcm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultIIDEndpoint = "https://iid.googleapis.com"
+	maxTopicTokens     = 1000
+)
+
+// TopicManagementResponse is a result of [Client.SubscribeToTopic] and [Client.UnsubscribeFromTopic].
+type TopicManagementResponse struct {
+	SuccessCount int
+	FailureCount int
+	Errors       []*TopicManagementError
+}
+
+// TopicManagementError is a failure for a single token.
+type TopicManagementError struct {
+	Index  int    // index of the token in the request.
+	Reason string // like NOT_FOUND or INVALID_ARGUMENT.
+}
+
+// SubscribeToTopic subscribes up to 1000 device tokens to the topic.
+//
+// See https://developers.google.com/instance-id/reference/server#manage_relationship_maps_for_multiple_app_instances
+func (c *Client) SubscribeToTopic(ctx context.Context, tokens []string, topic string) (*TopicManagementResponse, error) {
+	return c.manageTopic(ctx, ":batchAdd", tokens, topic)
+}
+
+// UnsubscribeFromTopic unsubscribes up to 1000 device tokens from the topic.
+func (c *Client) UnsubscribeFromTopic(ctx context.Context, tokens []string, topic string) (*TopicManagementResponse, error) {
+	return c.manageTopic(ctx, ":batchRemove", tokens, topic)
+}
+
+func (c *Client) manageTopic(ctx context.Context, op string, tokens []string, topic string) (*TopicManagementResponse, error) {
+	switch {
+	case len(tokens) == 0:
+		return nil, errors.New("tokens must not be empty")
+	case len(tokens) > maxTopicTokens:
+		return nil, fmt.Errorf("tokens must not contain more than %d elements", maxTopicTokens)
+	case slices.Contains(tokens, ""):
+		return nil, errors.New("tokens must not contain empty strings")
+	}
+	if err := ValidateTopic(topic); err != nil {
+		return nil, err
+	}
+
+	req := struct {
+		To     string   `json:"to"`
+		Tokens []string `json:"registration_tokens"`
+	}{
+		To:     "/topics/" + strings.TrimPrefix(topic, "/topics/"),
+		Tokens: tokens,
+	}
+
+	var resp struct {
+		Results []struct {
+			Error string `json:"error"`
+		} `json:"results"`
+	}
+	if err := c.doIID(ctx, http.MethodPost, "/iid/v1"+op, req, &resp); err != nil {
+		return nil, err
+	}
+
+	result := &TopicManagementResponse{}
+	for i, r := range resp.Results {
+		if r.Error == "" {
+			result.SuccessCount++
+			continue
+		}
+		result.FailureCount++
+		result.Errors = append(result.Errors, &TopicManagementError{
+			Index:  i,
+			Reason: r.Error,
+		})
+	}
+	return result, nil
+}
+
+// TokenInfo is metadata of a device token.
+//
+// See https://developers.google.com/instance-id/reference/server#get_information_about_app_instances
+type TokenInfo struct {
+	Application        string `json:"application"`
+	ApplicationVersion string `json:"applicationVersion,omitempty"`
+	AuthorizedEntity   string `json:"authorizedEntity"`
+	Platform           string `json:"platform"` // ANDROID, IOS or CHROME.
+	AttestStatus       string `json:"attestStatus,omitempty"`
+	AppSigner          string `json:"appSigner,omitempty"`
+	ConnectionType     string `json:"connectionType,omitempty"`
+	ConnectDate        string `json:"connectDate,omitempty"` // like 2015-05-12.
+
+	// Rel is set only when details are requested.
+	Rel *TokenInfoRel `json:"rel,omitempty"`
+}
+
+// TokenInfoRel contains relations of a device token.
+type TokenInfoRel struct {
+	Topics map[string]TokenInfoTopic `json:"topics,omitempty"`
+}
+
+// TokenInfoTopic is a topic subscription of a device token.
+type TokenInfoTopic struct {
+	AddDate string `json:"addDate"` // like 2015-07-30.
+}
+
+// TokenInfo returns metadata of the device token.
+// When details is true topic subscriptions are returned in [TokenInfo.Rel].
+func (c *Client) TokenInfo(ctx context.Context, token string, details bool) (*TokenInfo, error) {
+	if token == "" {
+		return nil, errors.New("token must not be empty")
+	}
+
+	path := "/iid/info/" + url.PathEscape(token)
+	if details {
+		path += "?details=true"
+	}
+
+	var info TokenInfo
+	if err := c.doIID(ctx, http.MethodGet, path, nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// doIID makes a request to the Instance ID API, body is not sent when nil.
+func (c *Client) doIID(ctx context.Context, method, path string, body, result any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.iidEndpoint+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("access_token_auth", "true")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("c.httpClient.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("io.ReadAll: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return newIIDError(resp, b)
+	}
+
+	if err := json.Unmarshal(b, result); err != nil {
+		return fmt.Errorf("json.Unmarshal(b, &resp): %w", err)
+	}
+	return nil
+}
+
+// newIIDError is like newFCMError but also handles plain {"error": "reason"} responses of Instance ID API.
+func newIIDError(resp *http.Response, body []byte) *FCMError {
+	fcmErr := newFCMError(resp, body)
+
+	var errResp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
+		fcmErr.Code = errResp.Error
+	}
+	return fcmErr
+}
@@ -0,0 +1,102 @@
+package fcm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestSubscribeToTopic(t *testing.T) {
+	var gotURL, gotBody, gotAuthHeader string
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		gotURL = req.URL.String()
+		gotAuthHeader = req.Header.Get("access_token_auth")
+		b, _ := io.ReadAll(req.Body)
+		gotBody = string(b)
+		return newResponse(http.StatusOK, `{"results":[{},{"error":"NOT_FOUND"},{},{"error":"INVALID_ARGUMENT"}]}`), nil
+	})
+
+	ctx := context.Background()
+	resp, err := client.SubscribeToTopic(ctx, []string{"t1", "t2", "t3", "t4"}, "/topics/news")
+	mustOk(t, err)
+	mustEqual(t, gotURL, "https://iid.googleapis.com/iid/v1:batchAdd")
+	mustEqual(t, gotAuthHeader, "true")
+	mustEqual(t, gotBody, `{"to":"/topics/news","registration_tokens":["t1","t2","t3","t4"]}`)
+	mustEqual(t, resp, &TopicManagementResponse{
+		SuccessCount: 2,
+		FailureCount: 2,
+		Errors: []*TopicManagementError{
+			{Index: 1, Reason: "NOT_FOUND"},
+			{Index: 3, Reason: "INVALID_ARGUMENT"},
+		},
+	})
+
+	resp, err = client.UnsubscribeFromTopic(ctx, []string{"t1"}, "news")
+	mustOk(t, err)
+	mustEqual(t, gotURL, "https://iid.googleapis.com/iid/v1:batchRemove")
+	mustEqual(t, gotBody, `{"to":"/topics/news","registration_tokens":["t1"]}`)
+	mustEqual(t, resp.SuccessCount, 2)
+}
+
+func TestSubscribeToTopicError(t *testing.T) {
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusUnauthorized, `{"error":"Unauthorized"}`), nil
+	})
+
+	ctx := context.Background()
+	_, err := client.SubscribeToTopic(ctx, []string{"t1"}, "news")
+	var fcmErr *FCMError
+	if !errors.As(err, &fcmErr) {
+		t.Fatalf("want FCMError, got %v", err)
+	}
+	mustEqual(t, fcmErr.StatusCode, http.StatusUnauthorized)
+	mustEqual(t, fcmErr.Code, "Unauthorized")
+
+	_, err = client.SubscribeToTopic(ctx, nil, "news")
+	mustFail(t, err)
+	_, err = client.SubscribeToTopic(ctx, []string{"t1", ""}, "news")
+	mustFail(t, err)
+	_, err = client.SubscribeToTopic(ctx, make([]string, 1001), "news")
+	mustFail(t, err)
+	_, err = client.SubscribeToTopic(ctx, []string{"t1"}, "bad topic")
+	mustFail(t, err)
+}
+
+func TestTokenInfo(t *testing.T) {
+	var gotURL, gotMethod string
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		gotURL, gotMethod = req.URL.String(), req.Method
+		return newResponse(http.StatusOK, `{
+			"application": "com.example.app",
+			"authorizedEntity": "123456782354",
+			"platform": "ANDROID",
+			"attestStatus": "ROOTED",
+			"appSigner": "1a2bc3d4e5",
+			"connectionType": "WIFI",
+			"connectDate": "2015-05-12",
+			"rel": {"topics": {"news": {"addDate": "2015-07-30"}}}
+		}`), nil
+	})
+
+	info, err := client.TokenInfo(context.Background(), "a:b/c", true)
+	mustOk(t, err)
+	mustEqual(t, gotMethod, http.MethodGet)
+	mustEqual(t, gotURL, "https://iid.googleapis.com/iid/info/a:b%2Fc?details=true")
+	mustEqual(t, info, &TokenInfo{
+		Application:      "com.example.app",
+		AuthorizedEntity: "123456782354",
+		Platform:         "ANDROID",
+		AttestStatus:     "ROOTED",
+		AppSigner:        "1a2bc3d4e5",
+		ConnectionType:   "WIFI",
+		ConnectDate:      "2015-05-12",
+		Rel: &TokenInfoRel{
+			Topics: map[string]TokenInfoTopic{"news": {AddDate: "2015-07-30"}},
+		},
+	})
+
+	_, err = client.TokenInfo(context.Background(), "", false)
+	mustFail(t, err)
+}
@@ -0,0 +1,85 @@
+package fcm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const iamCredentialsEndpoint = "https://iamcredentials.googleapis.com"
+
+// impersonateTokenSource exchanges base credentials for an access token of the target service account.
+//
+// See https://cloud.google.com/iam/docs/reference/credentials/rest/v1/projects.serviceAccounts/generateAccessToken
+type impersonateTokenSource struct {
+	client   *http.Client
+	endpoint string
+	target   string
+	scopes   []string
+}
+
+// newImpersonateTokenSource authorizes requests of the client with base token source.
+func newImpersonateTokenSource(base oauth2.TokenSource, target string, scopes []string, client *http.Client) *impersonateTokenSource {
+	return &impersonateTokenSource{
+		client: &http.Client{
+			Transport: &oauth2.Transport{
+				Base:   client.Transport,
+				Source: base,
+			},
+			Timeout: client.Timeout,
+		},
+		endpoint: iamCredentialsEndpoint,
+		target:   target,
+		scopes:   scopes,
+	}
+}
+
+func (ts *impersonateTokenSource) Token() (*oauth2.Token, error) {
+	reqBody := struct {
+		Scope    []string `json:"scope"`
+		Lifetime string   `json:"lifetime"`
+	}{
+		Scope:    ts.scopes,
+		Lifetime: "3600s",
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/projects/-/serviceAccounts/%s:generateAccessToken", ts.endpoint, url.PathEscape(ts.target))
+	resp, err := ts.client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("impersonate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("impersonate: code: %d, body: %s", resp.StatusCode, b)
+	}
+
+	var result struct {
+		AccessToken string    `json:"accessToken"`
+		ExpireTime  time.Time `json:"expireTime"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: result.AccessToken,
+		TokenType:   "Bearer",
+		Expiry:      result.ExpireTime,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package fcm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestImpersonateTokenSource(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody struct {
+		Scope []string `json:"scope"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"accessToken":"impersonated","expireTime":"2030-01-02T15:04:05Z"}`))
+	}))
+	defer srv.Close()
+
+	base := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "base"})
+	ts := newImpersonateTokenSource(base, "sender@test-project.iam.gserviceaccount.com", firebaseScopes, http.DefaultClient)
+	ts.endpoint = srv.URL
+
+	token, err := ts.Token()
+	mustOk(t, err)
+	mustEqual(t, token.AccessToken, "impersonated")
+	mustEqual(t, gotPath, "/v1/projects/-/serviceAccounts/sender@test-project.iam.gserviceaccount.com:generateAccessToken")
+	mustEqual(t, gotAuth, "Bearer base")
+	mustEqual(t, gotBody.Scope, firebaseScopes)
+}
+
+func TestNewClientImpersonate(t *testing.T) {
+	_, err := NewClient(Config{
+		Client:                    &fakeClient{},
+		ProjectID:                 "test-project",
+		ImpersonateServiceAccount: "sender@test-project.iam.gserviceaccount.com",
+	})
+	mustFail(t, err)
+
+	client, err := NewClient(Config{
+		ProjectID:                 "test-project",
+		TokenSource:               oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "base"}),
+		ImpersonateServiceAccount: "sender@test-project.iam.gserviceaccount.com",
+	})
+	mustOk(t, err)
+	mustEqual(t, client.tokenSource != nil, true)
+}
+
+func TestNewClientImpersonateBaseTransport(t *testing.T) {
+	var gotHost string
+	client, err := NewClient(Config{
+		ProjectID:                 "test-project",
+		TokenSource:               oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "base"}),
+		ImpersonateServiceAccount: "sender@test-project.iam.gserviceaccount.com",
+		BaseTransport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			gotHost = req.URL.Host
+			return newResponse(http.StatusOK, `{"accessToken":"impersonated","expireTime":"2030-01-02T15:04:05Z"}`), nil
+		}),
+	})
+	mustOk(t, err)
+
+	token, err := client.Token(context.Background())
+	mustOk(t, err)
+	mustEqual(t, token.AccessToken, "impersonated")
+	mustEqual(t, gotHost, "iamcredentials.googleapis.com")
+}
+
+func TestNewClientImpersonateTimeout(t *testing.T) {
+	client, err := NewClient(Config{
+		ProjectID:                 "test-project",
+		TokenSource:               oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "base"}),
+		ImpersonateServiceAccount: "sender@test-project.iam.gserviceaccount.com",
+		Timeout:                   10 * time.Millisecond,
+		BaseTransport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}),
+	})
+	mustOk(t, err)
+
+	_, err = client.Token(context.Background())
+	mustFail(t, err)
+}
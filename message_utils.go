@@ -0,0 +1,104 @@
+package fcm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+)
+
+// SetDataJSON marshals v as JSON and stores it under the key in [Message.Data].
+func SetDataJSON(m *Message, key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if m.Data == nil {
+		m.Data = make(map[string]string)
+	}
+	m.Data[key] = string(b)
+	return nil
+}
+
+// GetDataJSON unmarshals JSON stored under the key in [Message.Data] into v.
+func GetDataJSON(m *Message, key string, v any) error {
+	raw, ok := m.Data[key]
+	if !ok {
+		return fmt.Errorf("data key %q not found", key)
+	}
+	return json.Unmarshal([]byte(raw), v)
+}
+
+// MergeDataMaps merges data maps from left to right, so later maps override earlier ones.
+func MergeDataMaps(dms ...DataMap) DataMap {
+	res := make(DataMap)
+	for _, dm := range dms {
+		maps.Copy(res, dm)
+	}
+	return res
+}
+
+// SilentMessage returns a data-only message which wakes up the app in background without showing anything.
+//
+// Android priority is set to high, APNS gets background push type with priority 5 and content-available flag.
+func SilentMessage(token string, data map[string]string) *Message {
+	return &Message{
+		Token: token,
+		Data:  data,
+		Android: &AndroidConfig{
+			Priority: "high",
+		},
+		APNS: &APNSConfig{
+			Headers: map[string]string{
+				"apns-push-type": "background",
+				"apns-priority":  "5",
+			},
+			Payload: &APNSPayload{
+				Aps: &Aps{ContentAvailable: true},
+			},
+		},
+	}
+}
+
+// MarshalMessage returns the request body transmitted by [Client.Send]: {"message": {...}}.
+// The message is not validated.
+func MarshalMessage(message *Message) ([]byte, error) {
+	return json.Marshal(sendRequest{Message: message})
+}
+
+// WriteJSON validates the message and writes it to w in the FCM request format: {"message": {...}}.
+func WriteJSON(w io.Writer, message *Message) error {
+	if err := validateMessage(message); err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(sendRequest{Message: message})
+}
+
+// ReadJSON reads a message written by [WriteJSON] and validates it.
+func ReadJSON(r io.Reader) (*Message, error) {
+	var msg sendRequest
+	if err := json.NewDecoder(r).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("json.Decode: %w", err)
+	}
+	if msg.Message == nil {
+		return nil, errors.New("message field is missing")
+	}
+	if err := validateMessage(msg.Message); err != nil {
+		return nil, err
+	}
+	return msg.Message, nil
+}
+
+// ParseMessage decodes a bare message JSON (without "message" envelope) and validates it.
+func ParseMessage(b []byte) (*Message, error) {
+	var msg Message
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	if err := validateMessage(&msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
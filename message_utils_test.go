@@ -0,0 +1,166 @@
+package fcm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDataJSON(t *testing.T) {
+	type payload struct {
+		ID    int      `json:"id"`
+		Kind  string   `json:"kind"`
+		Items []string `json:"items"`
+	}
+
+	want := payload{ID: 42, Kind: "order", Items: []string{"a", "b"}}
+
+	var msg Message
+	mustOk(t, SetDataJSON(&msg, "payload", want))
+	mustEqual(t, msg.Data["payload"], `{"id":42,"kind":"order","items":["a","b"]}`)
+
+	var have payload
+	mustOk(t, GetDataJSON(&msg, "payload", &have))
+	mustEqual(t, have, want)
+
+	mustFail(t, GetDataJSON(&msg, "missing", &have))
+	mustFail(t, SetDataJSON(&msg, "bad", make(chan int)))
+}
+
+func TestSilentMessage(t *testing.T) {
+	msg := SilentMessage("token", map[string]string{"sync": "1"})
+	mustOk(t, msg.IsValid())
+
+	mustEqual(t, msg.Android.Priority, "high")
+	mustEqual(t, msg.APNS.Headers, map[string]string{
+		"apns-push-type": "background",
+		"apns-priority":  "5",
+	})
+
+	b, err := json.Marshal(msg.APNS.Payload)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"aps":{"content-available":1}}`)
+}
+
+func TestWriteReadJSON(t *testing.T) {
+	msg := &Message{
+		Topic: "news",
+		Data:  map[string]string{"k": "v"},
+		Notification: &Notification{
+			Title: "Hello",
+		},
+	}
+
+	var buf bytes.Buffer
+	mustOk(t, WriteJSON(&buf, msg))
+	mustEqual(t, buf.String(), `{"message":{"topic":"news","data":{"k":"v"},"notification":{"title":"Hello"}}}`+"\n")
+
+	got, err := ReadJSON(&buf)
+	mustOk(t, err)
+	mustEqual(t, got, msg)
+
+	mustFail(t, WriteJSON(&buf, &Message{}))
+
+	_, err = ReadJSON(strings.NewReader(`{"msg":{}}`))
+	mustFail(t, err)
+}
+
+func TestMergeDataMaps(t *testing.T) {
+	got := MergeDataMaps(
+		DataMap{"a": "1", "b": "1"},
+		nil,
+		DataMap{"b": "2", "c": "2"},
+		DataMap{"c": "3"},
+	)
+	mustEqual(t, got, DataMap{"a": "1", "b": "2", "c": "3"})
+
+	mustEqual(t, MergeDataMaps(), DataMap{})
+}
+
+func TestParseMessage(t *testing.T) {
+	b := []byte(`{
+		"token": "token",
+		"data": {"k": "v"},
+		"notification": {"title": "Hello", "body": "World"},
+		"android": {
+			"priority": "high",
+			"ttl": "3.5s",
+			"notification": {"color": "#FF0000", "notification_priority": "PRIORITY_HIGH"}
+		},
+		"apns": {
+			"headers": {"apns-priority": "10"},
+			"payload": {"aps": {"alert": "Hello", "badge": 1, "content-available": 1}, "custom": "value"}
+		},
+		"webpush": {
+			"headers": {"Urgency": "high"},
+			"notification": {"title": "Hello", "requireInteraction": true, "extra": 1}
+		}
+	}`)
+
+	msg, err := ParseMessage(b)
+	mustOk(t, err)
+	mustEqual(t, msg.Token, "token")
+	mustEqual(t, msg.Android.TTL, ptr(3500*time.Millisecond))
+	mustEqual(t, msg.Android.Notification.Priority, PriorityHigh)
+	mustEqual(t, msg.APNS.Payload.Aps.AlertString, "Hello")
+	mustEqual(t, msg.APNS.Payload.Aps.ContentAvailable, true)
+	mustEqual(t, msg.APNS.Payload.CustomData, map[string]any{"custom": "value"})
+	mustEqual(t, msg.Webpush.Notification.RequireInteraction, true)
+	mustEqual(t, msg.Webpush.Notification.CustomData, map[string]any{"extra": float64(1)})
+
+	_, err = ParseMessage([]byte(`{"token": "token", "topic": "news"}`))
+	mustFail(t, err)
+	_, err = ParseMessage([]byte(`{"token": "token", "android": {"notification": {"color": "red"}}}`))
+	mustFail(t, err)
+	_, err = ParseMessage([]byte(`{"token":"t","android":{"notification":{"light_settings":{"light_on_duration":"1s","light_off_duration":"1s"}}}}`))
+	mustFail(t, err)
+	_, err = ParseMessage([]byte(`{`))
+	mustFail(t, err)
+}
+
+func TestMarshalMessage(t *testing.T) {
+	msg := &Message{
+		Topic:        "/topics/news",
+		Data:         DataMap{"id": "42"},
+		Notification: &Notification{Title: "Hello", Body: "World"},
+		Android: &AndroidConfig{
+			Priority: "high",
+			TTL:      ptr(time.Hour),
+		},
+		APNS: &APNSConfig{
+			Headers: map[string]string{"apns-priority": "10"},
+			Payload: &APNSPayload{Aps: &Aps{Badge: ptr(1), Sound: "default"}},
+		},
+		Webpush: &WebpushConfig{
+			Headers: map[string]string{"Urgency": "high"},
+		},
+		FCMOptions: &FCMOptions{AnalyticsLabel: "campaign"},
+	}
+
+	const golden = `{"message":{"topic":"news",` +
+		`"data":{"id":"42"},` +
+		`"notification":{"title":"Hello","body":"World"},` +
+		`"android":{"ttl":"3600s","priority":"high"},` +
+		`"webpush":{"headers":{"Urgency":"high"}},` +
+		`"apns":{"headers":{"apns-priority":"10"},"payload":{"aps":{"badge":1,"sound":"default"}}},` +
+		`"fcm_options":{"analytics_label":"campaign"}}}`
+
+	var sent string
+	client := newTestClient(t, func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		sent = string(b)
+		return newResponse(http.StatusOK, `{"name":"1"}`), nil
+	})
+	_, err := client.Send(context.Background(), msg)
+	mustOk(t, err)
+
+	b, err := MarshalMessage(msg)
+	mustOk(t, err)
+	mustEqual(t, string(b), golden)
+	mustEqual(t, sent, golden)
+}
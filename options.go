@@ -0,0 +1,45 @@
+package fcm
+
+import (
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Option to configure [Client] created via [NewClientWithOptions].
+type Option func(*Config)
+
+// WithCredentials sets JSON credentials from Firebase project settings.
+func WithCredentials(creds []byte) Option {
+	return func(cfg *Config) { cfg.Credentials = creds }
+}
+
+// WithCredentialsFile sets path to JSON credentials file, see [Config.CredentialsFile].
+func WithCredentialsFile(path string) Option {
+	return func(cfg *Config) { cfg.CredentialsFile = path }
+}
+
+// WithTokenSource sets OAuth2 token source, used instead of credentials.
+func WithTokenSource(ts oauth2.TokenSource) Option {
+	return func(cfg *Config) { cfg.TokenSource = ts }
+}
+
+// WithEndpoint overrides default FCM endpoint, see [Config.Endpoint].
+func WithEndpoint(endpoint string) Option {
+	return func(cfg *Config) { cfg.Endpoint = endpoint }
+}
+
+// WithHTTPClient sets HTTP client which must handle authorization by itself.
+func WithHTTPClient(client httpClient) Option {
+	return func(cfg *Config) { cfg.Client = client }
+}
+
+// WithUserAgent sets User-Agent header for each request.
+func WithUserAgent(userAgent string) Option {
+	return func(cfg *Config) { cfg.UserAgent = userAgent }
+}
+
+// WithTimeout sets timeout for each HTTP request.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cfg *Config) { cfg.Timeout = timeout }
+}
@@ -0,0 +1,25 @@
+package fcm
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter throttles outbound requests, see [Config.RateLimiter].
+type RateLimiter interface {
+	// Wait blocks until a request is allowed or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// NewTokenBucketLimiter returns a [RateLimiter] allowing limit requests per second
+// with bursts of at most burst requests.
+// Panics if limit is not positive, burst less than 1 is treated as 1.
+//
+// The limiter applies to a single process, it does not coordinate across processes or instances.
+func NewTokenBucketLimiter(limit rate.Limit, burst int) RateLimiter {
+	if limit <= 0 {
+		panic("fcm: rate must be positive")
+	}
+	return rate.NewLimiter(limit, max(burst, 1))
+}
@@ -0,0 +1,111 @@
+package fcm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketBurst(t *testing.T) {
+	limiter := NewTokenBucketLimiter(1, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// 2 tokens are available at once, the 3rd one is a second away.
+	mustOk(t, limiter.Wait(ctx))
+	mustOk(t, limiter.Wait(ctx))
+	mustFail(t, limiter.Wait(ctx))
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	limiter := NewTokenBucketLimiter(1, 1)
+	mustOk(t, limiter.Wait(context.Background()))
+
+	// The limiter fails fast when the deadline is before the next token.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	mustFail(t, limiter.Wait(ctx))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err := limiter.Wait(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want canceled, got %v", err)
+	}
+}
+
+func TestSendRateLimiter(t *testing.T) {
+	limiter := &fakeLimiter{}
+	client, err := NewClient(Config{
+		Client: &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+			return newResponse(http.StatusOK, `{"name":"1"}`), nil
+		}},
+		ProjectID:   "test-project",
+		RateLimiter: limiter,
+	})
+	mustOk(t, err)
+
+	ctx := context.Background()
+	_, err = client.Send(ctx, &Message{Token: "token"})
+	mustOk(t, err)
+	mustEqual(t, limiter.calls, 1)
+
+	limiter.err = errors.New("limited")
+	_, err = client.Send(ctx, &Message{Token: "token"})
+	if !errors.Is(err, limiter.err) {
+		t.Fatalf("want limiter error, got %v", err)
+	}
+	mustEqual(t, limiter.calls, 2)
+}
+
+type fakeLimiter struct {
+	calls int
+	err   error
+}
+
+func (l *fakeLimiter) Wait(ctx context.Context) error {
+	l.calls++
+	return l.err
+}
+
+func TestSendRateLimit(t *testing.T) {
+	do := func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, `{"name":"1"}`), nil
+	}
+	client, err := NewClient(Config{
+		Client:    &fakeClient{do: do},
+		ProjectID: "test-project",
+		RateLimit: 100,
+		Burst:     2,
+	})
+	mustOk(t, err)
+
+	// 2 sends are allowed at once, 4 more take at least 40ms at 100 per second.
+	start := time.Now()
+	for range 6 {
+		_, err := client.Send(context.Background(), &Message{Token: "token"})
+		mustOk(t, err)
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Fatalf("want throughput capped, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Send(ctx, &Message{Token: "token"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want canceled, got %v", err)
+	}
+
+	_, err = NewClient(Config{Client: &fakeClient{do: do}, ProjectID: "test-project", RateLimit: -1})
+	mustFail(t, err)
+	_, err = NewClient(Config{
+		Client:      &fakeClient{do: do},
+		ProjectID:   "test-project",
+		RateLimit:   1,
+		RateLimiter: &fakeLimiter{},
+	})
+	mustFail(t, err)
+}
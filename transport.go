@@ -0,0 +1,141 @@
+package fcm
+
+import (
+	"crypto/tls"
+	"errors"
+	"maps"
+	"net/http"
+	"runtime/debug"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+func newHTTPClient(cfg Config) *http.Client {
+	return &http.Client{
+		Transport: newTransport(cfg),
+		Timeout:   cfg.Timeout,
+	}
+}
+
+// QuotaProjectKey is a context key to override quota project of a single request.
+// The value must be a GCP project ID string, it's sent as X-Goog-User-Project header.
+//
+// Credentials must have serviceusage.services.use permission on the quota project.
+// Ignored when Config.Client is set.
+type QuotaProjectKey struct{}
+
+type parameterTransport struct {
+	userAgent     string
+	quotaProject  string
+	requestReason string
+
+	base http.RoundTripper
+}
+
+func (t *parameterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt := t.base
+	if rt == nil {
+		return nil, errors.New("transport: no Transport specified")
+	}
+
+	newReq := *req
+	newReq.Header = make(http.Header)
+	maps.Copy(newReq.Header, req.Header)
+
+	if t.userAgent != "" {
+		newReq.Header.Set("User-Agent", t.userAgent)
+	}
+	newReq.Header.Set("X-Firebase-Client", firebaseClient())
+
+	quotaProject := t.quotaProject
+	if qp, ok := req.Context().Value(QuotaProjectKey{}).(string); ok && qp != "" {
+		quotaProject = qp
+	}
+	if quotaProject != "" {
+		newReq.Header.Set("X-Goog-User-Project", quotaProject)
+	}
+
+	return rt.RoundTrip(&newReq)
+}
+
+const modulePath = "github.com/cristalhq/fcm"
+
+// firebaseClient returns X-Firebase-Client header value for SDK usage attribution.
+var firebaseClient = sync.OnceValue(func() string {
+	return "go-fcm/" + moduleVersion()
+})
+
+// moduleVersion returns version of this module from build info or "dev" when unknown.
+func moduleVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	mod := &bi.Main
+	for _, dep := range bi.Deps {
+		if dep.Path == modulePath {
+			mod = dep
+			break
+		}
+	}
+	if mod.Path != modulePath || mod.Version == "" || mod.Version == "(devel)" {
+		return "dev"
+	}
+	return mod.Version
+}
+
+func newTransport(cfg Config) http.RoundTripper {
+	paramTransport := &parameterTransport{
+		userAgent: cfg.UserAgent,
+		base:      configBaseTransport(cfg),
+	}
+	var trans http.RoundTripper = paramTransport
+
+	if cfg.TokenSource == nil {
+		return trans
+	}
+
+	trans = &oauth2.Transport{
+		Base:   trans,
+		Source: cfg.TokenSource,
+	}
+	return trans
+}
+
+// configBaseTransport returns Config.BaseTransport or a default transport with Config.Proxy.
+// It's shared by FCM requests and token requests, so both go through the same proxy or dialer.
+func configBaseTransport(cfg Config) http.RoundTripper {
+	if cfg.BaseTransport != nil {
+		return cfg.BaseTransport
+	}
+	trans := newBaseTransport(cfg.DisableHTTP2)
+	if cfg.Proxy != nil {
+		trans.Proxy = cfg.Proxy
+	}
+	return trans
+}
+
+// newBaseTransport clones http.DefaultTransport, the clone negotiates HTTP/2 via ALPN unless disabled.
+func newBaseTransport(disableHTTP2 bool) *http.Transport {
+	trans := http.DefaultTransport.(*http.Transport).Clone()
+	if disableHTTP2 {
+		// A non-nil empty map disables HTTP/2 upgrade.
+		trans.ForceAttemptHTTP2 = false
+		trans.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return trans
+}
+
+// messagingScopes is the minimal scope to send messages, see [Config.MessagingScopeOnly].
+var messagingScopes = []string{"https://www.googleapis.com/auth/firebase.messaging"}
+
+var firebaseScopes = []string{
+	"https://www.googleapis.com/auth/cloud-platform",
+	"https://www.googleapis.com/auth/datastore",
+	"https://www.googleapis.com/auth/devstorage.full_control",
+	"https://www.googleapis.com/auth/firebase",
+	"https://www.googleapis.com/auth/identitytoolkit",
+	"https://www.googleapis.com/auth/userinfo.email",
+}
@@ -0,0 +1,119 @@
+package fcm
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestBaseTransportHTTP2(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	for _, disable := range []bool{false, true} {
+		trans := newBaseTransport(disable)
+		trans.TLSClientConfig = &tls.Config{RootCAs: srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+
+		resp, err := (&http.Client{Transport: trans}).Get(srv.URL)
+		mustOk(t, err)
+		resp.Body.Close()
+
+		if disable {
+			mustEqual(t, resp.ProtoMajor, 1)
+		} else {
+			mustEqual(t, resp.ProtoMajor, 2)
+		}
+	}
+}
+
+func TestParameterTransportFirebaseClient(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Firebase-Client")
+	}))
+	defer srv.Close()
+
+	client := newHTTPClient(Config{})
+	resp, err := client.Get(srv.URL)
+	mustOk(t, err)
+	defer resp.Body.Close()
+
+	mustEqual(t, moduleVersion(), "dev")
+	mustEqual(t, got, "go-fcm/dev")
+}
+
+func TestParameterTransportQuotaProject(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("X-Goog-User-Project"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &parameterTransport{
+		quotaProject: "static-project",
+		base:         newBaseTransport(false),
+	}}
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		context.WithValue(context.Background(), QuotaProjectKey{}, "tenant-project"),
+	} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		mustOk(t, err)
+		resp, err := client.Do(req)
+		mustOk(t, err)
+		resp.Body.Close()
+	}
+	mustEqual(t, got, []string{"static-project", "tenant-project"})
+}
+
+func TestConfigBaseTransport(t *testing.T) {
+	var gotMarker, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMarker, gotAuth = r.Header.Get("X-Marker"), r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	client := newHTTPClient(Config{
+		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "secret"}),
+		BaseTransport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Marker", "egress")
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	})
+	resp, err := client.Get(srv.URL)
+	mustOk(t, err)
+	defer resp.Body.Close()
+
+	mustEqual(t, gotMarker, "egress")
+	mustEqual(t, gotAuth, "Bearer secret")
+}
+
+func TestConfigProxy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	var gotURL string
+	client := newHTTPClient(Config{
+		Proxy: func(req *http.Request) (*url.URL, error) {
+			gotURL = req.URL.String()
+			return nil, nil
+		},
+	})
+	resp, err := client.Get(srv.URL)
+	mustOk(t, err)
+	defer resp.Body.Close()
+
+	mustEqual(t, gotURL, srv.URL)
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,391 @@
+package fcm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The file isn't named types_android.go like types_apns.go and types_webpush.go
+// because Go treats _android suffix as a GOOS constraint and skips the file on other platforms.
+
+// AndroidConfig contains messaging options specific to the Android platform.
+//
+// See https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages#androidconfig
+type AndroidConfig struct {
+	CollapseKey           string               `json:"collapse_key,omitempty"`
+	Priority              string               `json:"priority,omitempty"` // one of "normal" or "high"
+	TTL                   *time.Duration       `json:"-"`
+	RestrictedPackageName string               `json:"restricted_package_name,omitempty"`
+	Data                  DataMap              `json:"data,omitempty"` // if set, overrides [Message.Data] field.
+	Notification          *AndroidNotification `json:"notification,omitempty"`
+	FCMOptions            *AndroidFCMOptions   `json:"fcm_options,omitempty"`
+	DirectBootOK          bool                 `json:"direct_boot_ok,omitempty"`
+}
+
+func (a *AndroidConfig) MarshalJSON() ([]byte, error) {
+	var ttl string
+	if a.TTL != nil {
+		ttl = durationToString(*a.TTL)
+	}
+
+	type androidWrapper AndroidConfig
+
+	tmp := &struct {
+		TTL string `json:"ttl,omitempty"`
+		*androidWrapper
+	}{
+		TTL:            ttl,
+		androidWrapper: (*androidWrapper)(a),
+	}
+	return json.Marshal(tmp)
+}
+
+func (a *AndroidConfig) UnmarshalJSON(b []byte) error {
+	type androidWrapper AndroidConfig
+
+	tmp := struct {
+		TTL string `json:"ttl,omitempty"`
+		*androidWrapper
+	}{
+		androidWrapper: (*androidWrapper)(a),
+	}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	if tmp.TTL != "" {
+		ttl, err := stringToDuration(tmp.TTL)
+		if err != nil {
+			return err
+		}
+		a.TTL = &ttl
+	}
+	return nil
+}
+
+// AndroidNotification is a notification to send to Android devices.
+//
+// See https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages#androidnotification
+type AndroidNotification struct {
+	Title                 string                        `json:"title,omitempty"` // if set, overrides [Notification.Title] field.
+	Body                  string                        `json:"body,omitempty"`  // if set, overrides [Notification.Body] field.
+	Icon                  string                        `json:"icon,omitempty"`  // small icon drawable resource name (not a URL), FCM has no separate small_icon.
+	Color                 string                        `json:"color,omitempty"` // #RRGGBB format, tints the small icon.
+	Sound                 string                        `json:"sound,omitempty"`
+	Tag                   string                        `json:"tag,omitempty"`
+	ClickAction           string                        `json:"click_action,omitempty"`
+	BodyLocKey            string                        `json:"body_loc_key,omitempty"`
+	BodyLocArgs           []string                      `json:"body_loc_args,omitempty"`
+	TitleLocKey           string                        `json:"title_loc_key,omitempty"`
+	TitleLocArgs          []string                      `json:"title_loc_args,omitempty"`
+	ChannelID             string                        `json:"channel_id,omitempty"`
+	Ticker                string                        `json:"ticker,omitempty"`
+	Sticky                bool                          `json:"sticky,omitempty"`
+	EventTimestamp        *time.Time                    `json:"-"`
+	LocalOnly             bool                          `json:"local_only,omitempty"`
+	Priority              AndroidNotificationPriority   `json:"-"`
+	DefaultSound          bool                          `json:"default_sound,omitempty"` // plays the default sound, Sound must be empty.
+	DefaultVibrateTimings bool                          `json:"default_vibrate_timings,omitempty"`
+	DefaultLightSettings  bool                          `json:"default_light_settings,omitempty"`
+	VibrateTimingMillis   []int64                       `json:"-"`
+	Visibility            AndroidNotificationVisibility `json:"-"`
+	NotificationCount     *int                          `json:"notification_count,omitempty"`
+	LightSettings         *LightSettings                `json:"light_settings,omitempty"`
+	ImageURL              string                        `json:"image,omitempty"`
+	Proxy                 AndroidNotificationProxy      `json:"-"`
+	PublicVersion         *AndroidNotification          `json:"public_notification,omitempty"` // shown on the lock screen, requires [VisibilityPrivate].
+}
+
+// SetNotificationCount sets the number of items this notification represents, shown on the app icon badge.
+func (a *AndroidNotification) SetNotificationCount(n int) *AndroidNotification {
+	a.NotificationCount = &n
+	return a
+}
+
+// WithBadge sets NotificationCount, count must not be negative.
+func (a *AndroidNotification) WithBadge(count int) (*AndroidNotification, error) {
+	if count < 0 {
+		return nil, errors.New("badge count must not be negative")
+	}
+	return a.SetNotificationCount(count), nil
+}
+
+// ClearBadge sets NotificationCount to 0 which clears the badge explicitly.
+func (a *AndroidNotification) ClearBadge() *AndroidNotification {
+	return a.SetNotificationCount(0)
+}
+
+func (a *AndroidNotification) MarshalJSON() ([]byte, error) {
+	var priority string
+	if a.Priority != priorityUnknown {
+		priorities := map[AndroidNotificationPriority]string{
+			PriorityMin:     "PRIORITY_MIN",
+			PriorityLow:     "PRIORITY_LOW",
+			PriorityDefault: "PRIORITY_DEFAULT",
+			PriorityHigh:    "PRIORITY_HIGH",
+			PriorityMax:     "PRIORITY_MAX",
+		}
+		priority = priorities[a.Priority]
+	}
+
+	var visibility string
+	if a.Visibility != visibilityUnknown {
+		visibilities := map[AndroidNotificationVisibility]string{
+			VisibilityPrivate: "PRIVATE",
+			VisibilityPublic:  "PUBLIC",
+			VisibilitySecret:  "SECRET",
+		}
+		visibility = visibilities[a.Visibility]
+	}
+
+	var proxy string
+	if a.Proxy != proxyUnknown {
+		proxies := map[AndroidNotificationProxy]string{
+			ProxyAllow:             "ALLOW",
+			ProxyDeny:              "DENY",
+			ProxyIfPriorityLowered: "IF_PRIORITY_LOWERED",
+		}
+		proxy = proxies[a.Proxy]
+	}
+
+	var timestamp string
+	if a.EventTimestamp != nil {
+		timestamp = a.EventTimestamp.UTC().Format(rfc3339Zulu)
+	}
+
+	vibTimings := make([]string, 0, len(a.VibrateTimingMillis))
+	for _, t := range a.VibrateTimingMillis {
+		vibTimings = append(vibTimings, durationToString(time.Duration(t)*time.Millisecond))
+	}
+
+	type androidWrapper AndroidNotification
+	tmp := &struct {
+		EventTimestamp string   `json:"event_time,omitempty"`
+		Priority       string   `json:"notification_priority,omitempty"`
+		Visibility     string   `json:"visibility,omitempty"`
+		Proxy          string   `json:"proxy,omitempty"`
+		VibrateTimings []string `json:"vibrate_timings,omitempty"`
+		*androidWrapper
+	}{
+		EventTimestamp: timestamp,
+		Priority:       priority,
+		Visibility:     visibility,
+		Proxy:          proxy,
+		VibrateTimings: vibTimings,
+		androidWrapper: (*androidWrapper)(a),
+	}
+	return json.Marshal(tmp)
+}
+
+func (a *AndroidNotification) UnmarshalJSON(b []byte) error {
+	type androidWrapper AndroidNotification
+	tmp := struct {
+		EventTimestamp string   `json:"event_time,omitempty"`
+		Priority       string   `json:"notification_priority,omitempty"`
+		Visibility     string   `json:"visibility,omitempty"`
+		Proxy          string   `json:"proxy,omitempty"`
+		VibrateTimings []string `json:"vibrate_timings,omitempty"`
+		*androidWrapper
+	}{
+		androidWrapper: (*androidWrapper)(a),
+	}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+
+	if tmp.Priority != "" {
+		priorities := map[string]AndroidNotificationPriority{
+			"PRIORITY_MIN":     PriorityMin,
+			"PRIORITY_LOW":     PriorityLow,
+			"PRIORITY_DEFAULT": PriorityDefault,
+			"PRIORITY_HIGH":    PriorityHigh,
+			"PRIORITY_MAX":     PriorityMax,
+		}
+		if prio, ok := priorities[tmp.Priority]; ok {
+			a.Priority = prio
+		} else {
+			return fmt.Errorf("unknown priority value: %q", tmp.Priority)
+		}
+	}
+
+	if tmp.Visibility != "" {
+		visibilities := map[string]AndroidNotificationVisibility{
+			"PRIVATE": VisibilityPrivate,
+			"PUBLIC":  VisibilityPublic,
+			"SECRET":  VisibilitySecret,
+		}
+		if vis, ok := visibilities[tmp.Visibility]; ok {
+			a.Visibility = vis
+		} else {
+			return fmt.Errorf("unknown visibility value: %q", tmp.Visibility)
+		}
+	}
+
+	if tmp.Proxy != "" {
+		proxies := map[string]AndroidNotificationProxy{
+			"ALLOW":               ProxyAllow,
+			"DENY":                ProxyDeny,
+			"IF_PRIORITY_LOWERED": ProxyIfPriorityLowered,
+		}
+		if prox, ok := proxies[tmp.Proxy]; ok {
+			a.Proxy = prox
+		} else {
+			return fmt.Errorf("unknown proxy value: %q", tmp.Proxy)
+		}
+	}
+
+	if tmp.EventTimestamp != "" {
+		ts, err := time.Parse(rfc3339Zulu, tmp.EventTimestamp)
+		if err != nil {
+			// also accept numeric offsets and any fraction precision.
+			ts, err = time.Parse(time.RFC3339Nano, tmp.EventTimestamp)
+			if err != nil {
+				return err
+			}
+		}
+
+		a.EventTimestamp = &ts
+	}
+
+	if len(tmp.VibrateTimings) == 0 {
+		return nil
+	}
+
+	vibTimings := make([]int64, 0, len(tmp.VibrateTimings))
+	for _, t := range tmp.VibrateTimings {
+		vibTime, err := stringToDuration(t)
+		if err != nil {
+			return err
+		}
+
+		millis := int64(vibTime / time.Millisecond)
+		vibTimings = append(vibTimings, millis)
+	}
+	a.VibrateTimingMillis = vibTimings
+	return nil
+}
+
+// AndroidNotificationPriority represents the priority levels of a notification.
+type AndroidNotificationPriority int
+
+const (
+	priorityUnknown AndroidNotificationPriority = 0
+
+	// PriorityMin is the lowest notification priority.
+	// Notifications with this priority might not be shown to the user except under special circumstances, such as detailed notification logs.
+	PriorityMin AndroidNotificationPriority = 1
+
+	// PriorityLow is a lower notification priority.
+	// The UI may choose to show the notifications smaller, or at a different position in the list, compared with notifications with PriorityDefault.
+	PriorityLow AndroidNotificationPriority = 2
+
+	// PriorityDefault is the default notification priority.
+	// If the application does not prioritize its own notifications, use this value for all notifications.
+	PriorityDefault AndroidNotificationPriority = 3
+
+	// PriorityHigh is a higher notification priority.
+	// Use this for more important notifications or alerts.
+	// The UI may choose to show these notifications larger, or at a different position in the notification lists, compared with notifications with PriorityDefault.
+	PriorityHigh AndroidNotificationPriority = 4
+
+	// PriorityMax is the highest notification priority.
+	// Use this for the application's most important items that require the user's prompt attention or input.
+	PriorityMax AndroidNotificationPriority = 5
+)
+
+// AndroidNotificationVisibility represents the different visibility levels of a notification.
+type AndroidNotificationVisibility int
+
+const (
+	visibilityUnknown AndroidNotificationVisibility = 0
+
+	// VisibilityPrivate shows this notification on all lockscreens, but conceal sensitive or private information on secure lockscreens.
+	VisibilityPrivate AndroidNotificationVisibility = 1
+
+	// VisibilityPublic shows this notification in its entirety on all lockscreens.
+	VisibilityPublic AndroidNotificationVisibility = 2
+
+	// VisibilitySecret does not reveal any part of this notification on a secure lockscreen.
+	VisibilitySecret AndroidNotificationVisibility = 3
+)
+
+// AndroidNotificationProxy to control when a notification may be proxied.
+type AndroidNotificationProxy int
+
+const (
+	proxyUnknown AndroidNotificationProxy = 0
+
+	// ProxyAllow tries to proxy this notification.
+	ProxyAllow AndroidNotificationProxy = 1
+
+	// ProxyDeny does not proxy this notification.
+	ProxyDeny AndroidNotificationProxy = 2
+
+	// ProxyIfPriorityLowered only tries to proxy this notification if its AndroidConfig's Priority was lowered from high to normal on the device.
+	ProxyIfPriorityLowered AndroidNotificationProxy = 3
+)
+
+// MaxLEDDurationMillis is the longest LED on or off duration accepted by Android.
+const MaxLEDDurationMillis int64 = 60000
+
+// LightSettings to control notification LED.
+type LightSettings struct {
+	Color                  string
+	LightOnDurationMillis  int64
+	LightOffDurationMillis int64
+}
+
+func (l *LightSettings) MarshalJSON() ([]byte, error) {
+	clr, err := newColor(l.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp := struct {
+		Color            *color `json:"color"`
+		LightOnDuration  string `json:"light_on_duration"`
+		LightOffDuration string `json:"light_off_duration"`
+	}{
+		Color:            clr,
+		LightOnDuration:  durationToString(time.Duration(l.LightOnDurationMillis) * time.Millisecond),
+		LightOffDuration: durationToString(time.Duration(l.LightOffDurationMillis) * time.Millisecond),
+	}
+	return json.Marshal(tmp)
+}
+
+func (l *LightSettings) UnmarshalJSON(b []byte) error {
+	tmp := struct {
+		Color            *color `json:"color"`
+		LightOnDuration  string `json:"light_on_duration"`
+		LightOffDuration string `json:"light_off_duration"`
+	}{}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	if tmp.Color == nil {
+		return errors.New("light settings color is required")
+	}
+
+	on, err := stringToDuration(tmp.LightOnDuration)
+	if err != nil {
+		return err
+	}
+
+	off, err := stringToDuration(tmp.LightOffDuration)
+	if err != nil {
+		return err
+	}
+
+	l.Color = tmp.Color.toString()
+	l.LightOnDurationMillis = int64(on / time.Millisecond)
+	l.LightOffDurationMillis = int64(off / time.Millisecond)
+	return nil
+}
+
+// AndroidFCMOptions contains additional options for features provided by the FCM Android SDK.
+//
+// See https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages#androidfcmoptions
+type AndroidFCMOptions struct {
+	AnalyticsLabel string `json:"analytics_label,omitempty"`
+}
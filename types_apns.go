@@ -0,0 +1,419 @@
+package fcm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+)
+
+// APNSConfig contains messaging options specific to the Apple Push Notification Service (APNS).
+// https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages#apnsconfig
+//
+// See https://developer.apple.com/library/content/documentation/NetworkingInternet/Conceptual/RemoteNotificationsPG/CommunicatingwithAPNs.html
+type APNSConfig struct {
+	Headers    map[string]string `json:"headers,omitempty"`
+	Payload    *APNSPayload      `json:"payload,omitempty"`
+	FCMOptions *APNSFCMOptions   `json:"fcm_options,omitempty"`
+
+	// LiveActivityToken is a push token of a Live Activity, it differs from the APNs device token of the app.
+	LiveActivityToken string `json:"live_activity_token,omitempty"`
+
+	// DeviceTokenHint is the underlying APNs device token observed in some FCM payloads.
+	// It's only read from JSON and never sent, FCM rejects unknown fields.
+	// Experimental: the field is not documented by FCM and may be ignored or removed.
+	DeviceTokenHint string `json:"-"`
+}
+
+func (c *APNSConfig) UnmarshalJSON(b []byte) error {
+	type apnsConfigWrapper APNSConfig
+	tmp := struct {
+		DeviceToken string `json:"device_token"`
+		*apnsConfigWrapper
+	}{
+		apnsConfigWrapper: (*apnsConfigWrapper)(c),
+	}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	c.DeviceTokenHint = tmp.DeviceToken
+	return nil
+}
+
+// APNSPriority is a value of apns-priority header.
+type APNSPriority int
+
+const (
+	// APNSPriorityLowest prioritizes power considerations over all other factors and may delay delivery.
+	APNSPriorityLowest APNSPriority = 1
+
+	// APNSPriorityLow sends the notification based on power considerations on the device.
+	APNSPriorityLow APNSPriority = 5
+
+	// APNSPriorityHigh sends the notification immediately.
+	APNSPriorityHigh APNSPriority = 10
+)
+
+// WithPriority sets apns-priority header.
+func (c *APNSConfig) WithPriority(p APNSPriority) *APNSConfig {
+	c.setHeader("apns-priority", strconv.Itoa(int(p)))
+	return c
+}
+
+// WithExpiration sets apns-expiration header, APNS stores the notification until t when the device is offline.
+func (c *APNSConfig) WithExpiration(t time.Time) *APNSConfig {
+	c.setHeader("apns-expiration", strconv.FormatInt(t.Unix(), 10))
+	return c
+}
+
+// WithNoExpiration sets apns-expiration header to 0, the notification is discarded when the device is offline.
+func (c *APNSConfig) WithNoExpiration() *APNSConfig {
+	c.setHeader("apns-expiration", "0")
+	return c
+}
+
+func (c *APNSConfig) setHeader(key, value string) {
+	if c.Headers == nil {
+		c.Headers = make(map[string]string)
+	}
+	c.Headers[key] = value
+}
+
+// APNSPayload is the payload that can be included in an APNS message.
+//
+// The payload mainly consists of the aps dictionary. Additionally it may contain arbitrary
+// key-values pairs as custom data fields.
+//
+// See https://developer.apple.com/library/content/documentation/NetworkingInternet/Conceptual/RemoteNotificationsPG/PayloadKeyReference.html
+type APNSPayload struct {
+	Aps        *Aps           `json:"aps,omitempty"`
+	CustomData map[string]any `json:"-"`
+}
+
+// standardFields creates a map containing all the fields except the custom data.
+func (p *APNSPayload) standardFields() map[string]any {
+	return map[string]any{"aps": p.Aps}
+}
+
+func (p *APNSPayload) MarshalJSON() ([]byte, error) {
+	m := p.standardFields()
+	maps.Copy(m, p.CustomData)
+	return json.Marshal(m)
+}
+
+func (p *APNSPayload) UnmarshalJSON(b []byte) error {
+	type apnsPayloadWrapper APNSPayload
+
+	tmp := (*apnsPayloadWrapper)(p)
+	if err := json.Unmarshal(b, tmp); err != nil {
+		return err
+	}
+	allFields := make(map[string]any)
+	if err := json.Unmarshal(b, &allFields); err != nil {
+		return err
+	}
+	for k := range p.standardFields() {
+		delete(allFields, k)
+	}
+	if len(allFields) > 0 {
+		p.CustomData = allFields
+	}
+	return nil
+}
+
+// Aps represents the aps dictionary that may be included in an APNSPayload.
+//
+// AlertText allows to send a string alert even if it's empty, it's mutually exclusive with AlertString and Alert.
+// AlertText allows to send a string alert even if it's empty and takes precedence over AlertString.
+//
+// ContentAvailable and MutableContent are emitted only when true.
+// To emit the key explicitly (even as 0) use ContentAvailableExplicit and MutableContentExplicit,
+// which take precedence over the bool fields when non-nil.
+type Aps struct {
+	AlertString              string         `json:"-"`
+	AlertText                *string        `json:"-"`
+	Alert                    *ApsAlert      `json:"-"`
+	Badge                    *int           `json:"badge,omitempty"` // nil leaves the badge unchanged, 0 removes it.
+	Sound                    string         `json:"-"`
+	CriticalSound            *CriticalSound `json:"-"`
+	ContentAvailable         bool           `json:"-"`
+	ContentAvailableExplicit *bool          `json:"-"`
+	MutableContent           bool           `json:"-"`
+	MutableContentExplicit   *bool          `json:"-"`
+	Category                 string         `json:"category,omitempty"`
+	ThreadID                 string         `json:"thread-id,omitempty"`
+	InterruptionLevel        string         `json:"interruption-level,omitempty"` // one of "passive", "active", "time-sensitive" or "critical"
+	ContentState             map[string]any `json:"content-state,omitempty"`      // Live Activity state.
+	URLArguments             []string       `json:"url-args,omitempty"`           // macOS Safari only, fills placeholders of the URL.
+	CustomData               map[string]any `json:"-"`
+}
+
+func (a *Aps) hasAlert() bool {
+	return a.Alert != nil || a.AlertText != nil || a.AlertString != ""
+}
+
+func (a *Aps) isContentAvailable() bool {
+	if a.ContentAvailableExplicit != nil {
+		return *a.ContentAvailableExplicit
+	}
+	return a.ContentAvailable
+}
+
+// WithBadge sets Badge, count must not be negative.
+func (a *Aps) WithBadge(count int) (*Aps, error) {
+	if count < 0 {
+		return nil, errors.New("badge count must not be negative")
+	}
+	a.Badge = &count
+	return a, nil
+}
+
+// ClearBadge sets Badge to 0 which removes the badge from the app icon.
+func (a *Aps) ClearBadge() *Aps {
+	a.Badge = new(int)
+	return a
+}
+
+// apsKeys are keys of standard fields reserved even when a field is empty.
+var apsKeys = []string{
+	"alert", "badge", "sound", "content-available", "mutable-content",
+	"category", "thread-id", "interruption-level", "content-state", "url-args",
+}
+
+// standardFields creates a map containing all the fields except the custom data.
+func (a *Aps) standardFields() map[string]any {
+	m := make(map[string]any)
+	if a.Alert != nil {
+		m["alert"] = a.Alert
+	} else if a.AlertText != nil {
+		m["alert"] = *a.AlertText
+	} else if a.AlertString != "" {
+		m["alert"] = a.AlertString
+	}
+	if a.ContentAvailableExplicit != nil {
+		m["content-available"] = boolToInt(*a.ContentAvailableExplicit)
+	} else if a.ContentAvailable {
+		m["content-available"] = 1
+	}
+	if a.MutableContentExplicit != nil {
+		m["mutable-content"] = boolToInt(*a.MutableContentExplicit)
+	} else if a.MutableContent {
+		m["mutable-content"] = 1
+	}
+	if a.Badge != nil {
+		m["badge"] = *a.Badge
+	}
+	if a.CriticalSound != nil {
+		m["sound"] = a.CriticalSound
+	} else if a.Sound != "" {
+		m["sound"] = a.Sound
+	}
+	if a.Category != "" {
+		m["category"] = a.Category
+	}
+	if a.ThreadID != "" {
+		m["thread-id"] = a.ThreadID
+	}
+	if a.InterruptionLevel != "" {
+		m["interruption-level"] = a.InterruptionLevel
+	}
+	if a.ContentState != nil {
+		m["content-state"] = a.ContentState
+	}
+	if len(a.URLArguments) > 0 {
+		m["url-args"] = a.URLArguments
+	}
+	return m
+}
+
+func (a *Aps) MarshalJSON() ([]byte, error) {
+	m := a.standardFields()
+	maps.Copy(m, a.CustomData)
+	return json.Marshal(m)
+}
+
+func (a *Aps) UnmarshalJSON(b []byte) error {
+	type apsWrapper Aps
+	tmp := struct {
+		AlertObject         *json.RawMessage `json:"alert,omitempty"`
+		SoundObject         *json.RawMessage `json:"sound,omitempty"`
+		ContentAvailableInt *apsFlag         `json:"content-available,omitempty"`
+		MutableContentInt   *apsFlag         `json:"mutable-content,omitempty"`
+		BadgeInt            *apsBadge        `json:"badge,omitempty"`
+		*apsWrapper
+	}{
+		apsWrapper: (*apsWrapper)(a),
+	}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	a.ContentAvailable, a.ContentAvailableExplicit = intToFlag((*int)(tmp.ContentAvailableInt))
+	a.MutableContent, a.MutableContentExplicit = intToFlag((*int)(tmp.MutableContentInt))
+	a.Badge = (*int)(tmp.BadgeInt)
+	if tmp.AlertObject != nil {
+		if err := json.Unmarshal(*tmp.AlertObject, &a.Alert); err != nil {
+			a.Alert = nil
+			if err := json.Unmarshal(*tmp.AlertObject, &a.AlertString); err != nil {
+				return fmt.Errorf("failed to unmarshal alert as a struct or a string: %w", err)
+			}
+			if a.AlertString == "" {
+				a.AlertText = new(string)
+			}
+		}
+	}
+	if tmp.SoundObject != nil {
+		if err := json.Unmarshal(*tmp.SoundObject, &a.CriticalSound); err != nil {
+			a.CriticalSound = nil
+			if err := json.Unmarshal(*tmp.SoundObject, &a.Sound); err != nil {
+				return fmt.Errorf("failed to unmarshal sound as a struct or a string")
+			}
+		}
+	}
+
+	allFields := make(map[string]any)
+	if err := json.Unmarshal(b, &allFields); err != nil {
+		return err
+	}
+	for _, k := range apsKeys {
+		delete(allFields, k)
+	}
+	if len(allFields) > 0 {
+		a.CustomData = allFields
+	}
+	return nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// intToFlag converts an optional 0/1 value. Present 0 is reported as explicit false.
+func intToFlag(v *int) (flag bool, explicit *bool) {
+	switch {
+	case v == nil:
+		return false, nil
+	case *v == 1:
+		return true, nil
+	default:
+		return false, new(bool)
+	}
+}
+
+// apsFlag is an integer flag of aps dictionary which also accepts true and false sent by some tools.
+type apsFlag int
+
+func (f *apsFlag) UnmarshalJSON(b []byte) error {
+	var v bool
+	if err := json.Unmarshal(b, &v); err == nil {
+		*f = apsFlag(boolToInt(v))
+		return nil
+	}
+	return json.Unmarshal(b, (*int)(f))
+}
+
+// apsBadge is a badge number which also accepts a string like "3" sent by some systems.
+type apsBadge int
+
+func (b *apsBadge) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return json.Unmarshal(data, (*int)(b))
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("malformed badge %q: %w", s, err)
+	}
+	*b = apsBadge(n)
+	return nil
+}
+
+// CriticalSound is the sound payload that can be included in an Aps.
+type CriticalSound struct {
+	Critical bool     `json:"-"`
+	Name     string   `json:"name,omitempty"`
+	Volume   *float64 `json:"volume,omitempty"` // in [0, 1] interval, nil means not set.
+}
+
+// NewCriticalSound creates a [CriticalSound] with a non-empty name and volume in [0, 1] interval.
+func NewCriticalSound(name string, volume float64, critical bool) (*CriticalSound, error) {
+	if name == "" {
+		return nil, errors.New("critical sound requires a sound name")
+	}
+	cs := &CriticalSound{Name: name, Critical: critical}
+	return cs.WithVolume(volume)
+}
+
+// WithCritical sets Critical flag.
+func (cs *CriticalSound) WithCritical(critical bool) *CriticalSound {
+	cs.Critical = critical
+	return cs
+}
+
+// WithVolume sets Volume, it must be in [0, 1] interval.
+func (cs *CriticalSound) WithVolume(v float64) (*CriticalSound, error) {
+	if err := validateCriticalSoundVolume(v); err != nil {
+		return nil, err
+	}
+	cs.Volume = &v
+	return cs, nil
+}
+
+func (cs *CriticalSound) MarshalJSON() ([]byte, error) {
+	type criticalSoundWrapper CriticalSound
+	tmp := struct {
+		CriticalInt int `json:"critical,omitempty"`
+		*criticalSoundWrapper
+	}{
+		criticalSoundWrapper: (*criticalSoundWrapper)(cs),
+	}
+	if cs.Critical {
+		tmp.CriticalInt = 1
+	}
+	return json.Marshal(tmp)
+}
+
+func (cs *CriticalSound) UnmarshalJSON(b []byte) error {
+	type criticalSoundWrapper CriticalSound
+	tmp := struct {
+		CriticalInt int `json:"critical,omitempty"`
+		*criticalSoundWrapper
+	}{
+		criticalSoundWrapper: (*criticalSoundWrapper)(cs),
+	}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	cs.Critical = (tmp.CriticalInt == 1)
+	return nil
+}
+
+// ApsAlert is the alert payload that can be included in an Aps.
+//
+// See https://developer.apple.com/library/content/documentation/NetworkingInternet/Conceptual/RemoteNotificationsPG/PayloadKeyReference.html
+type ApsAlert struct {
+	Title           string   `json:"title,omitempty"` // if set, overrides [Notification.Title] field.
+	SubTitle        string   `json:"subtitle,omitempty"`
+	Body            string   `json:"body,omitempty"` // if set, overrides [Notification.Body] field.
+	LocKey          string   `json:"loc-key,omitempty"`
+	LocArgs         []string `json:"loc-args,omitempty"`
+	TitleLocKey     string   `json:"title-loc-key,omitempty"`
+	TitleLocArgs    []string `json:"title-loc-args,omitempty"`
+	SubTitleLocKey  string   `json:"subtitle-loc-key,omitempty"`
+	SubTitleLocArgs []string `json:"subtitle-loc-args,omitempty"`
+	ActionLocKey    string   `json:"action-loc-key,omitempty"`
+	LaunchImage     string   `json:"launch-image,omitempty"`     // image file name in the app bundle, for native apps.
+	LaunchImageURL  string   `json:"launch-image-url,omitempty"` // image URL, for web clips which have no app bundle.
+}
+
+// APNSFCMOptions contains additional options for features provided by the FCM Aps SDK.
+//
+// See https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages#apnsfcmoptions
+type APNSFCMOptions struct {
+	AnalyticsLabel string `json:"analytics_label,omitempty"`
+	ImageURL       string `json:"image,omitempty"`
+}
@@ -0,0 +1,315 @@
+package fcm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DataMap is a data payload of [Message], [AndroidConfig] and [WebpushConfig].
+type DataMap = map[string]string
+
+// Message to be sent via Firebase Cloud Messaging (FCM).
+// A Message must specify exactly one of Token, Topic or Condition fields.
+//
+// See https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages
+type Message struct {
+	Data         DataMap        `json:"data,omitempty"`
+	Notification *Notification  `json:"notification,omitempty"`
+	Android      *AndroidConfig `json:"android,omitempty"`
+	Webpush      *WebpushConfig `json:"webpush,omitempty"`
+	APNS         *APNSConfig    `json:"apns,omitempty"`
+	FCMOptions   *FCMOptions    `json:"fcm_options,omitempty"`
+
+	Token     string `json:"token,omitempty"`
+	Topic     string `json:"-"` // "/topics/" prefix is optional, it's stripped on marshal, so unmarshal gives a bare name.
+	Condition string `json:"condition,omitempty"`
+}
+
+func (m Message) IsValid() error {
+	return validateMessage(&m)
+}
+
+// ValidateStrict is like [Message.IsValid] but also performs checks
+// that may reject messages accepted by FCM, such as number of localization arguments.
+func (m Message) ValidateStrict() error {
+	return validateMessageStrict(&m)
+}
+
+// IsBroadcast reports whether the message targets many devices via Topic or Condition.
+func (m *Message) IsBroadcast() bool {
+	return m.Topic != "" || m.Condition != ""
+}
+
+// TargetKind returns kind of the message target, one of "token", "topic" or "condition", and its value.
+// Returns empty strings when not exactly one target is set.
+func (m *Message) TargetKind() (kind, value string) {
+	var n int
+	for _, t := range [...]struct{ kind, value string }{
+		{"token", m.Token},
+		{"topic", m.Topic},
+		{"condition", m.Condition},
+	} {
+		if t.value != "" {
+			kind, value = t.kind, t.value
+			n++
+		}
+	}
+	if n != 1 {
+		return "", ""
+	}
+	return kind, value
+}
+
+// ConditionOp combines topics in a condition, see [Message.WithTopics].
+type ConditionOp string
+
+const (
+	// ConditionOr matches devices subscribed to any of the topics.
+	ConditionOr ConditionOp = "||"
+
+	// ConditionAnd matches devices subscribed to all of the topics.
+	ConditionAnd ConditionOp = "&&"
+)
+
+// maxConditionTopics is a limit of topics in a condition set by FCM.
+const maxConditionTopics = 5
+
+// WithTopics sets Condition combining the topics with op, Token and Topic are cleared.
+// Useful to target several topics at once, FCM allows at most 5 topics in a condition.
+func (m *Message) WithTopics(op ConditionOp, topics ...string) (*Message, error) {
+	switch {
+	case op != ConditionOr && op != ConditionAnd:
+		return nil, fmt.Errorf("unknown condition operator %q", op)
+	case len(topics) == 0:
+		return nil, errors.New("topics must not be empty")
+	case len(topics) > maxConditionTopics:
+		return nil, fmt.Errorf("condition may have at most %d topics", maxConditionTopics)
+	}
+
+	parts := make([]string, len(topics))
+	for i, topic := range topics {
+		if err := ValidateTopic(topic); err != nil {
+			return nil, fmt.Errorf("topic %q: %w", topic, err)
+		}
+		parts[i] = fmt.Sprintf("'%s' in topics", strings.TrimPrefix(topic, "/topics/"))
+	}
+
+	m.Token, m.Topic = "", ""
+	m.Condition = strings.Join(parts, " "+string(op)+" ")
+	return m, nil
+}
+
+// IsDataOnly reports whether the message has data but no notification for any platform.
+// Such messages are handled by the app itself and on Android require high priority to wake it up.
+func (m *Message) IsDataOnly() bool {
+	hasData := len(m.Data) > 0 || (m.Android != nil && len(m.Android.Data) > 0)
+	switch {
+	case !hasData, m.Notification != nil:
+		return false
+	case m.Android != nil && m.Android.Notification != nil:
+		return false
+	case m.Webpush != nil && m.Webpush.Notification != nil:
+		return false
+	case m.APNS != nil && m.APNS.Payload != nil && m.APNS.Payload.Aps != nil && m.APNS.Payload.Aps.hasAlert():
+		return false
+	default:
+		return true
+	}
+}
+
+// Normalize fills defaults of optional fields: initializes nil Data
+// and trims spaces around Notification.ImageURL left by external systems.
+// Empty Android.Priority is kept, so FCM applies its default, high for notification messages.
+// Returns the receiver for chaining.
+func (m *Message) Normalize() *Message {
+	if m.Data == nil {
+		m.Data = DataMap{}
+	}
+	if m.Notification != nil {
+		m.Notification.ImageURL = strings.TrimSpace(m.Notification.ImageURL)
+	}
+	return m
+}
+
+// DeepEqual reports whether messages have equal fields, maps are compared by contents.
+// Topic is compared without optional "/topics/" prefix. Nil and empty maps or slices are not equal.
+func (m *Message) DeepEqual(other *Message) bool {
+	if m == nil || other == nil {
+		return m == other
+	}
+	a, b := *m, *other
+	a.Topic = strings.TrimPrefix(a.Topic, "/topics/")
+	b.Topic = strings.TrimPrefix(b.Topic, "/topics/")
+	return reflect.DeepEqual(a, b)
+}
+
+// Platform is a set of platforms targeted by a [Message].
+type Platform uint8
+
+const (
+	PlatformAndroid Platform = 1 << iota
+	PlatformAPNS
+	PlatformWebpush
+)
+
+func (p Platform) String() string {
+	var names []string
+	if p&PlatformAndroid != 0 {
+		names = append(names, "android")
+	}
+	if p&PlatformAPNS != 0 {
+		names = append(names, "apns")
+	}
+	if p&PlatformWebpush != 0 {
+		names = append(names, "webpush")
+	}
+	return strings.Join(names, "|")
+}
+
+// Platforms returns platforms with a specific config in the message.
+func (m *Message) Platforms() Platform {
+	var p Platform
+	if m.HasAndroid() {
+		p |= PlatformAndroid
+	}
+	if m.HasAPNS() {
+		p |= PlatformAPNS
+	}
+	if m.HasWebpush() {
+		p |= PlatformWebpush
+	}
+	return p
+}
+
+// HasAndroid reports whether the message has Android config.
+func (m *Message) HasAndroid() bool { return m.Android != nil }
+
+// HasAPNS reports whether the message has APNS config.
+func (m *Message) HasAPNS() bool { return m.APNS != nil }
+
+// HasWebpush reports whether the message has Webpush config.
+func (m *Message) HasWebpush() bool { return m.Webpush != nil }
+
+// AndroidData returns data delivered to Android devices.
+// [AndroidConfig.Data] when set overrides Data entirely.
+func (m *Message) AndroidData() DataMap {
+	if m.Android != nil && len(m.Android.Data) > 0 {
+		return m.Android.Data
+	}
+	return m.Data
+}
+
+// EstimateSize returns the size of the message marshaled to JSON.
+// It's an estimate because the request envelope and server-side fields are not counted.
+func (m *Message) EstimateSize() (int, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// IsWithinFCMSizeLimit reports whether [Message.EstimateSize] fits FCM limit of 4096 bytes.
+func (m *Message) IsWithinFCMSizeLimit() (bool, error) {
+	size, err := m.EstimateSize()
+	if err != nil {
+		return false, err
+	}
+	return size <= defaultMaxDataSize, nil
+}
+
+func (m *Message) MarshalJSON() ([]byte, error) {
+	type messageWrapper Message
+
+	tmp := &struct {
+		BareTopic string `json:"topic,omitempty"`
+		*messageWrapper
+	}{
+		BareTopic:      strings.TrimPrefix(m.Topic, "/topics/"),
+		messageWrapper: (*messageWrapper)(m),
+	}
+	return json.Marshal(tmp)
+}
+
+func (m *Message) UnmarshalJSON(b []byte) error {
+	type messageWrapper Message
+
+	tmp := struct {
+		BareTopic string `json:"topic,omitempty"`
+		*messageWrapper
+	}{
+		messageWrapper: (*messageWrapper)(m),
+	}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	m.Topic = tmp.BareTopic
+
+	return nil
+}
+
+// Notification is the basic notification template to use across all platforms.
+//
+// See https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages#notification
+type Notification struct {
+	Title    string `json:"title,omitempty"`
+	Body     string `json:"body,omitempty"`
+	ImageURL string `json:"image,omitempty"`
+}
+
+// NotificationBuilder builds a [Notification], useful as a template for many notifications.
+// It's not safe for concurrent use.
+type NotificationBuilder struct {
+	n Notification
+}
+
+// NewNotificationBuilder creates an empty [NotificationBuilder].
+func NewNotificationBuilder() *NotificationBuilder {
+	return &NotificationBuilder{}
+}
+
+// SetTitle sets Title of the notification.
+func (b *NotificationBuilder) SetTitle(title string) *NotificationBuilder {
+	b.n.Title = title
+	return b
+}
+
+// SetBody sets Body of the notification.
+func (b *NotificationBuilder) SetBody(body string) *NotificationBuilder {
+	b.n.Body = body
+	return b
+}
+
+// SetImageURL sets ImageURL of the notification.
+func (b *NotificationBuilder) SetImageURL(imageURL string) *NotificationBuilder {
+	b.n.ImageURL = imageURL
+	return b
+}
+
+// Build validates and returns a new notification, later changes of the builder don't affect it.
+func (b *NotificationBuilder) Build() (*Notification, error) {
+	n := b.n
+	if err := validateNotification(&n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// MustBuild is like [NotificationBuilder.Build] but panics on error.
+func (b *NotificationBuilder) MustBuild() *Notification {
+	n, err := b.Build()
+	if err != nil {
+		panic(fmt.Sprintf("fcm: %v", err))
+	}
+	return n
+}
+
+// FCMOptions contains additional options to use across all platforms.
+//
+// See https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages#fcmoptions
+type FCMOptions struct {
+	AnalyticsLabel string `json:"analytics_label,omitempty"`
+}
@@ -0,0 +1,740 @@
+package fcm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApsExplicitFlags(t *testing.T) {
+	testCases := []struct {
+		aps  *Aps
+		want string
+	}{
+		{&Aps{}, `{}`},
+		{&Aps{ContentAvailable: true, MutableContent: true}, `{"content-available":1,"mutable-content":1}`},
+		{&Aps{ContentAvailableExplicit: new(bool)}, `{"content-available":0}`},
+		{&Aps{ContentAvailable: true, ContentAvailableExplicit: new(bool)}, `{"content-available":0}`},
+		{&Aps{MutableContentExplicit: new(bool)}, `{"mutable-content":0}`},
+	}
+
+	for _, tc := range testCases {
+		b, err := json.Marshal(tc.aps)
+		mustOk(t, err)
+		mustEqual(t, string(b), tc.want)
+
+		var aps Aps
+		mustOk(t, json.Unmarshal(b, &aps))
+		b2, err := json.Marshal(&aps)
+		mustOk(t, err)
+		mustEqual(t, string(b2), tc.want)
+	}
+}
+
+func TestApsFlagsBoolForm(t *testing.T) {
+	testCases := []struct {
+		payload string
+		want    string
+	}{
+		{`{"content-available":1,"mutable-content":1}`, `{"content-available":1,"mutable-content":1}`},
+		{`{"content-available":true,"mutable-content":true}`, `{"content-available":1,"mutable-content":1}`},
+		{`{"content-available":false,"mutable-content":0}`, `{"content-available":0,"mutable-content":0}`},
+	}
+
+	for _, tc := range testCases {
+		var aps Aps
+		mustOk(t, json.Unmarshal([]byte(tc.payload), &aps))
+		b, err := json.Marshal(&aps)
+		mustOk(t, err)
+		mustEqual(t, string(b), tc.want)
+	}
+
+	var aps Aps
+	mustOk(t, json.Unmarshal([]byte(`{"content-available":true}`), &aps))
+	mustEqual(t, aps.ContentAvailable, true)
+	mustEqual(t, aps.CustomData, map[string]any(nil))
+
+	mustFail(t, json.Unmarshal([]byte(`{"content-available":"yes"}`), &aps))
+}
+
+func TestApsBadgeString(t *testing.T) {
+	testCases := []struct {
+		payload string
+		want    *int
+	}{
+		{`{}`, nil},
+		{`{"badge":3}`, ptr(3)},
+		{`{"badge":"3"}`, ptr(3)},
+		{`{"badge":"0"}`, ptr(0)},
+	}
+
+	for _, tc := range testCases {
+		var aps Aps
+		mustOk(t, json.Unmarshal([]byte(tc.payload), &aps))
+		mustEqual(t, aps.Badge, tc.want)
+		mustEqual(t, aps.CustomData, map[string]any(nil))
+	}
+
+	var aps Aps
+	mustOk(t, json.Unmarshal([]byte(`{"badge":"3"}`), &aps))
+	b, err := json.Marshal(&aps)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"badge":3}`)
+
+	mustFail(t, json.Unmarshal([]byte(`{"badge":"three"}`), &aps))
+}
+
+func TestApsClearBadge(t *testing.T) {
+	b, err := json.Marshal(&Aps{})
+	mustOk(t, err)
+	mustEqual(t, string(b), `{}`)
+
+	b, err = json.Marshal((&Aps{}).ClearBadge())
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"badge":0}`)
+}
+
+func TestWithBadge(t *testing.T) {
+	n, err := (&AndroidNotification{}).WithBadge(3)
+	mustOk(t, err)
+	mustEqual(t, n.NotificationCount, ptr(3))
+	mustEqual(t, n.ClearBadge().NotificationCount, ptr(0))
+	_, err = n.WithBadge(-1)
+	mustFail(t, err)
+	mustEqual(t, n.NotificationCount, ptr(0))
+
+	aps, err := (&Aps{}).WithBadge(3)
+	mustOk(t, err)
+	mustEqual(t, aps.Badge, ptr(3))
+	mustEqual(t, aps.ClearBadge().Badge, ptr(0))
+	_, err = aps.WithBadge(-1)
+	mustFail(t, err)
+	mustEqual(t, aps.Badge, ptr(0))
+
+	msg := Message{Token: "token", APNS: &APNSConfig{Payload: &APNSPayload{Aps: &Aps{Badge: ptr(-1)}}}}
+	mustFail(t, msg.IsValid())
+}
+
+func TestAndroidNotificationIconRoundTrip(t *testing.T) {
+	n := &AndroidNotification{
+		Icon:     "ic_stat_notify",
+		Color:    "#FF0000",
+		ImageURL: "https://example.com/large.png",
+	}
+
+	b, err := json.Marshal(n)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"icon":"ic_stat_notify","color":"#FF0000","image":"https://example.com/large.png"}`)
+
+	var got AndroidNotification
+	mustOk(t, json.Unmarshal(b, &got))
+	mustEqual(t, got.Icon, n.Icon)
+	mustEqual(t, got.Color, n.Color)
+	mustEqual(t, got.ImageURL, n.ImageURL)
+}
+
+func TestWebpushFCMOptionsJSON(t *testing.T) {
+	testCases := []struct {
+		opts *WebpushFCMOptions
+		want string
+	}{
+		{&WebpushFCMOptions{}, `{}`},
+		{&WebpushFCMOptions{Link: "https://example.com"}, `{"link":"https://example.com"}`},
+		{&WebpushFCMOptions{AnalyticsLabel: "campaign_1"}, `{"analytics_label":"campaign_1"}`},
+		{
+			&WebpushFCMOptions{Link: "https://example.com", AnalyticsLabel: "campaign_1"},
+			`{"link":"https://example.com","analytics_label":"campaign_1"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		b, err := json.Marshal(tc.opts)
+		mustOk(t, err)
+		mustEqual(t, string(b), tc.want)
+		testRoundTrip(t, tc.opts)
+
+		msg := Message{Token: "token", Webpush: &WebpushConfig{FCMOptions: tc.opts}}
+		mustOk(t, msg.IsValid())
+	}
+
+	msg := Message{
+		Token:   "token",
+		Webpush: &WebpushConfig{FCMOptions: &WebpushFCMOptions{AnalyticsLabel: "no spaces allowed"}},
+	}
+	mustFail(t, msg.IsValid())
+}
+
+func TestCriticalSoundVolume(t *testing.T) {
+	testCases := []struct {
+		volume *float64
+		want   string
+	}{
+		{nil, `{"critical":1,"name":"alarm"}`},
+		{ptr(0.0), `{"critical":1,"name":"alarm","volume":0}`},
+		{ptr(0.5), `{"critical":1,"name":"alarm","volume":0.5}`},
+		{ptr(1.0), `{"critical":1,"name":"alarm","volume":1}`},
+	}
+
+	for _, tc := range testCases {
+		cs := &CriticalSound{Critical: true, Name: "alarm", Volume: tc.volume}
+
+		b, err := json.Marshal(cs)
+		mustOk(t, err)
+		mustEqual(t, string(b), tc.want)
+
+		var got CriticalSound
+		mustOk(t, json.Unmarshal(b, &got))
+		mustEqual(t, got, *cs)
+
+		msg := Message{
+			Token: "token",
+			APNS:  &APNSConfig{Payload: &APNSPayload{Aps: &Aps{CriticalSound: cs}}},
+		}
+		mustOk(t, msg.IsValid())
+	}
+
+	msg := Message{
+		Token: "token",
+		APNS:  &APNSConfig{Payload: &APNSPayload{Aps: &Aps{CriticalSound: &CriticalSound{Volume: ptr(1.5)}}}},
+	}
+	mustFail(t, msg.IsValid())
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestMessageTopicRoundTrip(t *testing.T) {
+	for _, topic := range []string{"news", "/topics/news"} {
+		msg := &Message{Topic: topic}
+
+		b1, err := json.Marshal(msg)
+		mustOk(t, err)
+		mustEqual(t, string(b1), `{"topic":"news"}`)
+
+		var got Message
+		mustOk(t, json.Unmarshal(b1, &got))
+		mustEqual(t, got.Topic, "news")
+
+		b2, err := json.Marshal(&got)
+		mustOk(t, err)
+		mustEqual(t, string(b2), string(b1))
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	eventTime := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	ttl := 90*time.Minute + 500*time.Millisecond
+
+	aps := &Aps{
+		Alert: &ApsAlert{
+			Title:        "Title",
+			Body:         "Body",
+			TitleLocKey:  "TITLE_KEY",
+			TitleLocArgs: []string{"a"},
+		},
+		Badge:            ptr(3),
+		CriticalSound:    &CriticalSound{Critical: true, Name: "alarm", Volume: ptr(0.5)},
+		ContentAvailable: true,
+		Category:         "NEW_MESSAGE",
+		ThreadID:         "thread-1",
+		CustomData:       map[string]any{"custom": "value"},
+	}
+
+	androidNotification := &AndroidNotification{
+		Title:                "Title",
+		Color:                "#0A0B0C",
+		EventTimestamp:       &eventTime,
+		Priority:             PriorityHigh,
+		Visibility:           VisibilityPrivate,
+		Proxy:                ProxyDeny,
+		VibrateTimingMillis:  []int64{100, 1500},
+		NotificationCount:    ptr(2),
+		DefaultLightSettings: true,
+		LightSettings: &LightSettings{
+			Color:                  "#01020304",
+			LightOnDurationMillis:  100,
+			LightOffDurationMillis: 250,
+		},
+	}
+
+	androidConfig := &AndroidConfig{
+		CollapseKey:  "key",
+		Priority:     "high",
+		TTL:          &ttl,
+		Data:         DataMap{"k": "v"},
+		Notification: androidNotification,
+	}
+
+	webpushNotification := &WebpushNotification{
+		Actions:         []*WebpushNotificationAction{{Action: "open", Title: "Open"}},
+		Title:           "Title",
+		Direction:       "ltr",
+		Data:            map[string]any{"nested": "data"},
+		Renotify:        true,
+		TimestampMillis: ptr(int64(1700000000000)),
+		Vibrate:         []int{100, 200},
+		CustomData:      map[string]any{"custom": "value"},
+	}
+
+	msg := &Message{
+		Token:        "token",
+		Data:         DataMap{"k": "v"},
+		Notification: &Notification{Title: "Title", Body: "Body"},
+		Android:      androidConfig,
+		Webpush: &WebpushConfig{
+			Headers:      map[string]string{"Urgency": "high"},
+			Notification: webpushNotification,
+			FCMOptions:   &WebpushFCMOptions{Link: "https://example.com"},
+		},
+		APNS: &APNSConfig{
+			Headers: map[string]string{"apns-priority": "10"},
+			Payload: &APNSPayload{Aps: aps, CustomData: map[string]any{"extra": "1"}},
+		},
+		FCMOptions: &FCMOptions{AnalyticsLabel: "label"},
+	}
+
+	testRoundTrip(t, aps)
+	testRoundTrip(t, androidNotification)
+	testRoundTrip(t, androidConfig)
+	testRoundTrip(t, webpushNotification)
+	testRoundTrip(t, msg)
+
+	var gotNotification AndroidNotification
+	b, err := json.Marshal(androidNotification)
+	mustOk(t, err)
+	mustOk(t, json.Unmarshal(b, &gotNotification))
+	mustEqual(t, *gotNotification.EventTimestamp, eventTime)
+	mustEqual(t, gotNotification.VibrateTimingMillis, androidNotification.VibrateTimingMillis)
+	mustEqual(t, gotNotification.LightSettings, androidNotification.LightSettings)
+}
+
+func TestJSONRoundTripEmpty(t *testing.T) {
+	testRoundTrip(t, &AndroidNotification{})
+	testRoundTrip(t, &WebpushNotification{})
+	testRoundTrip(t, &Aps{})
+
+	var got AndroidNotification
+	mustOk(t, json.Unmarshal([]byte(`{}`), &got))
+	mustEqual(t, got, AndroidNotification{})
+}
+
+// testRoundTrip checks that v survives marshal→unmarshal→marshal.
+func testRoundTrip[T any](tb testing.TB, v *T) {
+	tb.Helper()
+
+	b1, err := json.Marshal(v)
+	mustOk(tb, err)
+
+	got := new(T)
+	mustOk(tb, json.Unmarshal(b1, got))
+
+	b2, err := json.Marshal(got)
+	mustOk(tb, err)
+	mustEqual(tb, string(b2), string(b1))
+}
+
+func TestAndroidNotificationEventTime(t *testing.T) {
+	want := time.Date(2014, 10, 2, 15, 1, 23, 45123456, time.UTC)
+
+	testCases := []string{
+		"2014-10-02T15:01:23.045123456Z",
+		"2014-10-02T15:01:23.045123456+00:00",
+		"2014-10-02T18:01:23.045123456+03:00",
+		"2014-10-02T15:01:23.045123456-00:00",
+	}
+
+	for _, ts := range testCases {
+		var n AndroidNotification
+		mustOk(t, json.Unmarshal([]byte(`{"event_time":"`+ts+`"}`), &n))
+		mustEqual(t, n.EventTimestamp.Equal(want), true)
+	}
+
+	var n AndroidNotification
+	mustOk(t, json.Unmarshal([]byte(`{"event_time":"2014-10-02T15:01:23Z"}`), &n))
+	mustEqual(t, n.EventTimestamp.Equal(want.Truncate(time.Second)), true)
+
+	mustFail(t, json.Unmarshal([]byte(`{"event_time":"2014-10-02 15:01:23"}`), &n))
+}
+
+func TestApsEmptyAlert(t *testing.T) {
+	aps := &Aps{AlertText: new(string)}
+
+	b, err := json.Marshal(aps)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"alert":""}`)
+
+	var got Aps
+	mustOk(t, json.Unmarshal(b, &got))
+	mustEqual(t, *got.AlertText, "")
+	testRoundTrip(t, aps)
+
+	b, err = json.Marshal(&Aps{AlertString: ""})
+	mustOk(t, err)
+	mustEqual(t, string(b), `{}`)
+
+	for _, aps := range []*Aps{
+		{AlertText: new(string), Alert: &ApsAlert{Title: "t"}},
+		{AlertText: new(string), AlertString: "text"},
+	} {
+		msg := Message{Token: "token", APNS: &APNSConfig{Payload: &APNSPayload{Aps: aps}}}
+		mustFail(t, msg.IsValid())
+	}
+}
+
+func TestApsContentState(t *testing.T) {
+	aps := &Aps{
+		InterruptionLevel: "time-sensitive",
+		ContentState: map[string]any{
+			"score":  map[string]any{"home": 1.0, "away": 2.0},
+			"status": "live",
+		},
+	}
+
+	b, err := json.Marshal(aps)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"content-state":{"score":{"away":2,"home":1},"status":"live"},"interruption-level":"time-sensitive"}`)
+
+	var got Aps
+	mustOk(t, json.Unmarshal(b, &got))
+	mustEqual(t, got.ContentState, aps.ContentState)
+	mustEqual(t, got.InterruptionLevel, aps.InterruptionLevel)
+	mustEqual(t, len(got.CustomData), 0)
+
+	msg := Message{Token: "token", APNS: &APNSConfig{Payload: &APNSPayload{Aps: aps}}}
+	mustOk(t, msg.IsValid())
+
+	aps.InterruptionLevel = "passive"
+	mustFail(t, msg.IsValid())
+
+	aps.InterruptionLevel = "loud"
+	mustFail(t, msg.IsValid())
+}
+
+func TestAPNSConfigWithPriority(t *testing.T) {
+	cfg := (&APNSConfig{}).WithPriority(APNSPriorityHigh)
+
+	b, err := json.Marshal(cfg)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"headers":{"apns-priority":"10"}}`)
+
+	var got APNSConfig
+	mustOk(t, json.Unmarshal(b, &got))
+	mustEqual(t, got.Headers["apns-priority"], "10")
+
+	msg := Message{Token: "token", APNS: got.WithPriority(APNSPriorityLow)}
+	mustOk(t, msg.IsValid())
+	mustEqual(t, got.Headers["apns-priority"], "5")
+
+	got.WithPriority(APNSPriorityLowest)
+	mustOk(t, msg.IsValid())
+	mustEqual(t, got.Headers["apns-priority"], "1")
+
+	msg.APNS.Headers = map[string]string{"APNS-Priority": "7"}
+	mustFail(t, msg.IsValid())
+}
+
+func TestAPNSConfigWithExpiration(t *testing.T) {
+	cfg := (&APNSConfig{}).WithExpiration(time.Unix(1700000000, 0))
+	mustEqual(t, cfg.Headers["apns-expiration"], "1700000000")
+
+	msg := Message{Token: "token", APNS: cfg}
+	mustOk(t, msg.IsValid())
+	mustEqual(t, cfg.WithNoExpiration().Headers["apns-expiration"], "0")
+	mustOk(t, msg.IsValid())
+
+	for _, v := range []string{"", "-1", "tomorrow", "1.5"} {
+		msg.APNS.Headers = map[string]string{"APNS-Expiration": v}
+		mustFail(t, msg.IsValid())
+	}
+}
+
+func TestWebpushApplyHeaders(t *testing.T) {
+	cfg := &WebpushConfig{Headers: map[string]string{"Topic": "news"}}
+	mustOk(t, cfg.ApplyHeaders(WebpushHeaders{
+		Urgency: UrgencyHigh,
+		TTL:     ptr(90*time.Second + 500*time.Millisecond),
+	}))
+	mustEqual(t, cfg.Headers, map[string]string{
+		"Topic":   "news",
+		"Urgency": "high",
+		"TTL":     "90",
+	})
+
+	msg := Message{Token: "token", Webpush: cfg}
+	mustOk(t, msg.IsValid())
+
+	mustFail(t, cfg.ApplyHeaders(WebpushHeaders{Urgency: "urgent"}))
+	mustFail(t, cfg.ApplyHeaders(WebpushHeaders{TTL: ptr(-time.Second)}))
+
+	mustOk(t, cfg.ApplyHeaders(WebpushHeaders{TTL: ptr(time.Duration(0))}))
+	mustEqual(t, cfg.Headers["TTL"], "0")
+	mustOk(t, cfg.ApplyHeaders(WebpushHeaders{Urgency: UrgencyLow}))
+	mustEqual(t, cfg.Headers["TTL"], "0")
+
+	cfg.Headers["urgency"] = "urgent"
+	mustFail(t, msg.IsValid())
+}
+
+func TestWebpushWithTopic(t *testing.T) {
+	cfg := (&WebpushConfig{}).WithTopic("score_update-42")
+	mustEqual(t, cfg.Headers, map[string]string{"Topic": "score_update-42"})
+
+	msg := Message{Token: "token", Webpush: cfg}
+	mustOk(t, msg.IsValid())
+
+	for _, topic := range []string{"", "score update", "news.today", strings.Repeat("a", 33)} {
+		cfg.WithTopic(topic)
+		mustFail(t, msg.IsValid())
+	}
+}
+
+func TestMessageEstimateSize(t *testing.T) {
+	msg := &Message{Token: "token", Data: DataMap{"k": "v"}}
+	size, err := msg.EstimateSize()
+	mustOk(t, err)
+	mustEqual(t, size, len(`{"data":{"k":"v"},"token":"token"}`))
+
+	ok, err := msg.IsWithinFCMSizeLimit()
+	mustOk(t, err)
+	mustEqual(t, ok, true)
+
+	msg.Data["k"] = strings.Repeat("v", 4096)
+	ok, err = msg.IsWithinFCMSizeLimit()
+	mustOk(t, err)
+	mustEqual(t, ok, false)
+
+	msg.Webpush = &WebpushConfig{Notification: &WebpushNotification{Data: func() {}}}
+	_, err = msg.EstimateSize()
+	mustFail(t, err)
+}
+
+func TestWebpushNotificationDataCollision(t *testing.T) {
+	n := &WebpushNotification{
+		Data:       map[string]any{"k": "v"},
+		CustomData: map[string]any{"extra": 1},
+	}
+	b, err := json.Marshal(n)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"data":{"k":"v"},"extra":1}`)
+
+	n = &WebpushNotification{CustomData: map[string]any{"data": "custom"}}
+	b, err = json.Marshal(n)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"data":"custom"}`)
+
+	n.Data = map[string]any{"k": "v"}
+	_, err = json.Marshal(n)
+	mustFail(t, err)
+
+	msg := Message{Token: "token", Webpush: &WebpushConfig{Notification: n}}
+	mustFail(t, msg.IsValid())
+}
+
+func TestAndroidNotificationPublicVersion(t *testing.T) {
+	n := &AndroidNotification{
+		Title:      "New message",
+		Visibility: VisibilityPrivate,
+		PublicVersion: &AndroidNotification{
+			Title:      "You have a new message",
+			Visibility: VisibilityPublic,
+		},
+	}
+	testRoundTrip(t, n)
+
+	b, err := json.Marshal(n)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"visibility":"PRIVATE","title":"New message","public_notification":{"visibility":"PUBLIC","title":"You have a new message"}}`)
+
+	msg := Message{Token: "token", Android: &AndroidConfig{Notification: n}}
+	mustOk(t, msg.IsValid())
+
+	n.PublicVersion.Color = "red"
+	mustFail(t, msg.IsValid())
+
+	n.PublicVersion.Color = ""
+	n.Visibility = VisibilityPublic
+	mustFail(t, msg.IsValid())
+}
+
+func TestMessagePlatforms(t *testing.T) {
+	msg := &Message{Token: "token"}
+	mustEqual(t, msg.Platforms(), Platform(0))
+	mustEqual(t, msg.Platforms().String(), "")
+
+	msg.Android = &AndroidConfig{}
+	mustEqual(t, msg.Platforms(), PlatformAndroid)
+	mustEqual(t, msg.HasAndroid(), true)
+	mustEqual(t, msg.HasAPNS(), false)
+
+	msg.APNS = &APNSConfig{}
+	msg.Webpush = &WebpushConfig{}
+	mustEqual(t, msg.Platforms(), PlatformAndroid|PlatformAPNS|PlatformWebpush)
+	mustEqual(t, msg.Platforms().String(), "android|apns|webpush")
+
+	msg.Android = nil
+	mustEqual(t, msg.Platforms().String(), "apns|webpush")
+}
+
+func TestMessageTargetKind(t *testing.T) {
+	testCases := []struct {
+		msg         *Message
+		kind, value string
+	}{
+		{&Message{Token: "t1"}, "token", "t1"},
+		{&Message{Topic: "news"}, "topic", "news"},
+		{&Message{Condition: "'a' in topics"}, "condition", "'a' in topics"},
+		{&Message{}, "", ""},
+		{&Message{Token: "t1", Topic: "news"}, "", ""},
+	}
+
+	for _, tc := range testCases {
+		kind, value := tc.msg.TargetKind()
+		mustEqual(t, kind, tc.kind)
+		mustEqual(t, value, tc.value)
+	}
+}
+
+func TestMessageWithTopics(t *testing.T) {
+	msg, err := (&Message{Topic: "a"}).WithTopics(ConditionOr, "a", "/topics/b")
+	mustOk(t, err)
+	mustEqual(t, msg.Condition, "'a' in topics || 'b' in topics")
+	mustEqual(t, msg.Topic, "")
+	mustOk(t, msg.IsValid())
+
+	msg, err = (&Message{}).WithTopics(ConditionAnd, "a", "b", "c")
+	mustOk(t, err)
+	mustEqual(t, msg.Condition, "'a' in topics && 'b' in topics && 'c' in topics")
+
+	_, err = (&Message{}).WithTopics(ConditionOr)
+	mustFail(t, err)
+	_, err = (&Message{}).WithTopics("!", "a")
+	mustFail(t, err)
+	_, err = (&Message{}).WithTopics(ConditionOr, "a", "bad topic")
+	mustFail(t, err)
+	_, err = (&Message{}).WithTopics(ConditionOr, "a", "b", "c", "d", "e", "f")
+	mustFail(t, err)
+}
+
+func TestNotificationBuilder(t *testing.T) {
+	b := NewNotificationBuilder().SetTitle("Hello").SetBody("World")
+	n, err := b.Build()
+	mustOk(t, err)
+	mustEqual(t, n, &Notification{Title: "Hello", Body: "World"})
+
+	n2 := b.SetImageURL("https://example.com/image.png").MustBuild()
+	mustEqual(t, n2.ImageURL, "https://example.com/image.png")
+	mustEqual(t, n.ImageURL, "")
+
+	_, err = b.SetImageURL("not a url").Build()
+	mustFail(t, err)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("want panic")
+		}
+	}()
+	b.MustBuild()
+}
+
+func TestMessageDeepEqual(t *testing.T) {
+	newMessage := func() *Message {
+		return &Message{
+			Topic: "news",
+			Data:  DataMap{"a": "1", "b": "2"},
+			APNS:  &APNSConfig{Payload: &APNSPayload{Aps: &Aps{Badge: ptr(1)}}},
+		}
+	}
+
+	msg := newMessage()
+	mustEqual(t, msg.DeepEqual(newMessage()), true)
+
+	other := newMessage()
+	other.Topic = "/topics/news"
+	mustEqual(t, msg.DeepEqual(other), true)
+
+	other.APNS.Payload.Aps.Badge = ptr(2)
+	mustEqual(t, msg.DeepEqual(other), false)
+
+	other = newMessage()
+	other.Data["c"] = "3"
+	mustEqual(t, msg.DeepEqual(other), false)
+
+	mustEqual(t, msg.DeepEqual(nil), false)
+	mustEqual(t, (*Message)(nil).DeepEqual(nil), true)
+}
+
+func FuzzMessageDeepEqual(f *testing.F) {
+	f.Add([]byte(`{"token":"token","data":{"k":"v"}}`), []byte(`{"topic":"news","data":{"k":"v"}}`))
+	f.Add([]byte(`{"apns":{"payload":{"aps":{"alert":"Hello"},"custom":1}}}`), []byte(`{"webpush":{"notification":{"data":[1]}}}`))
+
+	f.Fuzz(func(t *testing.T, a, b []byte) {
+		var m1, m2 Message
+		if json.Unmarshal(a, &m1) != nil || json.Unmarshal(b, &m2) != nil {
+			return
+		}
+		if m1.DeepEqual(&m2) != m2.DeepEqual(&m1) {
+			t.Fatal("DeepEqual is not symmetric")
+		}
+	})
+}
+
+func TestAPNSConfigDeviceTokenHint(t *testing.T) {
+	var got APNSConfig
+	mustOk(t, json.Unmarshal([]byte(`{"live_activity_token":"live-token","device_token":"apns-token"}`), &got))
+	mustEqual(t, got.LiveActivityToken, "live-token")
+	mustEqual(t, got.DeviceTokenHint, "apns-token")
+
+	// The hint is never sent, FCM rejects unknown fields.
+	b, err := json.Marshal(&got)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"live_activity_token":"live-token"}`)
+}
+
+func TestMessageNormalize(t *testing.T) {
+	msgs := []*Message{
+		{Token: "token"},
+		{Topic: "news", Notification: &Notification{Title: "Hello"}},
+		{Token: "token", Data: DataMap{"k": "v"}, Android: &AndroidConfig{TTL: ptr(time.Hour)}},
+		{Condition: "'a' in topics", Android: &AndroidConfig{Priority: "high"}},
+	}
+
+	for _, msg := range msgs {
+		mustOk(t, msg.IsValid())
+		mustOk(t, msg.Normalize().IsValid())
+		if msg.Data == nil {
+			t.Fatal("want non-nil data")
+		}
+	}
+	mustEqual(t, msgs[2].Android.Priority, "")
+	mustEqual(t, msgs[3].Android.Priority, "high")
+
+	b, err := json.Marshal(msgs[0])
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"token":"token"}`)
+
+	msg := &Message{Token: "token", Notification: &Notification{ImageURL: " https://example.com/a.png\n"}}
+	mustFail(t, msg.IsValid())
+	mustOk(t, msg.Normalize().IsValid())
+	mustEqual(t, msg.Notification.ImageURL, "https://example.com/a.png")
+}
+
+func TestNewCriticalSound(t *testing.T) {
+	cs, err := NewCriticalSound("alarm.aiff", 0.8, true)
+	mustOk(t, err)
+	mustEqual(t, cs, &CriticalSound{Critical: true, Name: "alarm.aiff", Volume: ptr(0.8)})
+
+	cs, err = cs.WithCritical(false).WithVolume(1)
+	mustOk(t, err)
+	mustEqual(t, cs, &CriticalSound{Name: "alarm.aiff", Volume: ptr(1.0)})
+
+	_, err = cs.WithVolume(1.5)
+	mustFail(t, err)
+	mustEqual(t, *cs.Volume, 1.0)
+
+	_, err = NewCriticalSound("", 0.5, true)
+	mustFail(t, err)
+	_, err = NewCriticalSound("alarm.aiff", -0.1, true)
+	mustFail(t, err)
+}
@@ -0,0 +1,124 @@
+package fcm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const rfc3339Zulu = "2006-01-02T15:04:05.000000000Z"
+
+type color struct {
+	Red   float64 `json:"red"`
+	Green float64 `json:"green"`
+	Blue  float64 `json:"blue"`
+	Alpha float64 `json:"alpha"`
+}
+
+func newColor(clr string) (*color, error) {
+	if !colorWithAlphaPattern.MatchString(clr) {
+		return nil, fmt.Errorf("color must be in #RRGGBB or #RRGGBBAA form: %q", clr)
+	}
+
+	red, err := strconv.ParseInt(clr[1:3], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", clr, err)
+	}
+
+	green, err := strconv.ParseInt(clr[3:5], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", clr, err)
+	}
+
+	blue, err := strconv.ParseInt(clr[5:7], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", clr, err)
+	}
+
+	alpha := int64(255)
+	if len(clr) == 9 {
+		alpha, err = strconv.ParseInt(clr[7:9], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", clr, err)
+		}
+	}
+
+	return &color{
+		Red:   float64(red) / 255.0,
+		Green: float64(green) / 255.0,
+		Blue:  float64(blue) / 255.0,
+		Alpha: float64(alpha) / 255.0,
+	}, nil
+}
+
+func (c *color) toString() string {
+	red := int(math.Round(c.Red * 255.0))
+	green := int(math.Round(c.Green * 255.0))
+	blue := int(math.Round(c.Blue * 255.0))
+	alpha := int(math.Round(c.Alpha * 255.0))
+	if alpha == 255 {
+		return fmt.Sprintf("#%02X%02X%02X", red, green, blue)
+	}
+	return fmt.Sprintf("#%02X%02X%02X%02X", red, green, blue, alpha)
+}
+
+func durationToString(d time.Duration) string {
+	seconds := int64(d / time.Second)
+	nanos := int64(d % time.Second)
+	if nanos == 0 {
+		return fmt.Sprintf("%ds", seconds)
+	}
+
+	// Both parts have the sign of d, keep it only in front, so -0.5s isn't lost.
+	sign := ""
+	if d < 0 {
+		sign, seconds, nanos = "-", -seconds, -nanos
+	}
+	return fmt.Sprintf("%s%d.%09ds", sign, seconds, nanos)
+}
+
+func stringToDuration(s string) (time.Duration, error) {
+	segments := strings.Split(strings.TrimSuffix(s, "s"), ".")
+	if len(segments) != 1 && len(segments) != 2 {
+		return 0, fmt.Errorf("incorrect number of segments in ttl: %q", s)
+	}
+
+	seconds, err := strconv.ParseInt(segments[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", s, err)
+	}
+	if seconds > math.MaxInt64/int64(time.Second) || seconds < math.MinInt64/int64(time.Second) {
+		return 0, fmt.Errorf("duration %s is out of range", s)
+	}
+
+	ttl := time.Duration(seconds) * time.Second
+	if len(segments) == 2 {
+		frac := segments[1]
+		if frac == "" || len(frac) > 9 || strings.Trim(frac, "0123456789") != "" {
+			return 0, fmt.Errorf("malformed fractional seconds in %s", s)
+		}
+		// Fraction is a decimal, so "5" is 500000000 nanoseconds.
+		nanos, err := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse %s: %w", s, err)
+		}
+
+		d := time.Duration(nanos)
+		switch {
+		case strings.HasPrefix(segments[0], "-"):
+			if ttl < math.MinInt64+d {
+				return 0, fmt.Errorf("duration %s is out of range", s)
+			}
+			ttl -= d
+		default:
+			if ttl > math.MaxInt64-d {
+				return 0, fmt.Errorf("duration %s is out of range", s)
+			}
+			ttl += d
+		}
+	}
+
+	return ttl, nil
+}
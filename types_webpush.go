@@ -0,0 +1,198 @@
+package fcm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// WebpushConfig contains messaging options specific to the WebPush protocol.
+// https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages#webpushconfig
+//
+// See https://tools.ietf.org/html/rfc8030#section-5
+type WebpushConfig struct {
+	Headers      map[string]string    `json:"headers,omitempty"`
+	Data         DataMap              `json:"data,omitempty"` // if set, overrides [Message.Data] field.
+	Notification *WebpushNotification `json:"notification,omitempty"`
+	FCMOptions   *WebpushFCMOptions   `json:"fcm_options,omitempty"`
+}
+
+// WebpushUrgency is a value of Urgency header.
+//
+// See https://tools.ietf.org/html/rfc8030#section-5.3
+type WebpushUrgency string
+
+const (
+	UrgencyVeryLow WebpushUrgency = "very-low"
+	UrgencyLow     WebpushUrgency = "low"
+	UrgencyNormal  WebpushUrgency = "normal"
+	UrgencyHigh    WebpushUrgency = "high"
+)
+
+func (u WebpushUrgency) isValid() bool {
+	switch u {
+	case UrgencyVeryLow, UrgencyLow, UrgencyNormal, UrgencyHigh:
+		return true
+	default:
+		return false
+	}
+}
+
+// WebpushHeaders are typed headers of the WebPush protocol, unset fields are not rendered.
+//
+// See https://tools.ietf.org/html/rfc8030#section-5
+type WebpushHeaders struct {
+	Urgency WebpushUrgency
+	TTL     *time.Duration // rounded down to seconds, 0 means deliver now or drop.
+}
+
+// ApplyHeaders renders typed headers into Headers map keeping other headers.
+func (c *WebpushConfig) ApplyHeaders(h WebpushHeaders) error {
+	switch {
+	case h.Urgency != "" && !h.Urgency.isValid():
+		return fmt.Errorf("unknown urgency: %q", h.Urgency)
+	case h.TTL != nil && *h.TTL < 0:
+		return errors.New("ttl must not be negative")
+	}
+
+	if h.Urgency != "" {
+		c.setHeader("Urgency", string(h.Urgency))
+	}
+	if h.TTL != nil {
+		c.setHeader("TTL", strconv.FormatInt(int64(*h.TTL/time.Second), 10))
+	}
+	return nil
+}
+
+// WebpushTopic is a name of Topic header, a pending notification with the same topic is replaced.
+//
+// See https://tools.ietf.org/html/rfc8030#section-5.4
+const WebpushTopic = "Topic"
+
+// WithTopic sets Topic header, it must be at most 32 characters of URL-safe base64 alphabet.
+func (c *WebpushConfig) WithTopic(topic string) *WebpushConfig {
+	c.setHeader(WebpushTopic, topic)
+	return c
+}
+
+func (c *WebpushConfig) setHeader(key, value string) {
+	if c.Headers == nil {
+		c.Headers = make(map[string]string)
+	}
+	c.Headers[key] = value
+}
+
+// WebpushNotificationAction represents an action that can be performed upon receiving a WebPush notification.
+type WebpushNotificationAction struct {
+	Action string `json:"action,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Icon   string `json:"icon,omitempty"`
+}
+
+// WebpushNotification is a notification to send via WebPush protocol.
+//
+// See https://developer.mozilla.org/en-US/docs/Web/API/notification/Notification
+type WebpushNotification struct {
+	Actions            []*WebpushNotificationAction `json:"actions,omitempty"`
+	Title              string                       `json:"title,omitempty"` // if set, overrides [Notification.Title] field.
+	Body               string                       `json:"body,omitempty"`  // if set, overrides [Notification.Body] field.
+	Icon               string                       `json:"icon,omitempty"`
+	Badge              string                       `json:"badge,omitempty"`
+	Direction          string                       `json:"dir,omitempty"`  // one of 'ltr' or 'rtl'
+	Data               any                          `json:"data,omitempty"` // must not be set together with "data" key in CustomData.
+	Image              string                       `json:"image,omitempty"`
+	Language           string                       `json:"lang,omitempty"`
+	Renotify           bool                         `json:"renotify,omitempty"`
+	RequireInteraction bool                         `json:"requireInteraction,omitempty"`
+	Silent             bool                         `json:"silent,omitempty"`
+	Tag                string                       `json:"tag,omitempty"`
+	TimestampMillis    *int64                       `json:"timestamp,omitempty"`
+	Vibrate            []int                        `json:"vibrate,omitempty"`
+	CustomData         map[string]any               `json:"-"`
+}
+
+// webpushNotificationKeys are keys of standard fields reserved even when a field is empty.
+var webpushNotificationKeys = []string{
+	"actions", "title", "body", "icon", "badge", "dir", "data", "image", "lang",
+	"renotify", "requireInteraction", "silent", "tag", "timestamp", "vibrate",
+}
+
+// standardFields creates a map containing all the fields except the custom data.
+func (n *WebpushNotification) standardFields() map[string]any {
+	m := make(map[string]any)
+	addNonEmpty := func(key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	addTrue := func(key string, value bool) {
+		if value {
+			m[key] = value
+		}
+	}
+	if len(n.Actions) > 0 {
+		m["actions"] = n.Actions
+	}
+	addNonEmpty("title", n.Title)
+	addNonEmpty("body", n.Body)
+	addNonEmpty("icon", n.Icon)
+	addNonEmpty("badge", n.Badge)
+	addNonEmpty("dir", n.Direction)
+	addNonEmpty("image", n.Image)
+	addNonEmpty("lang", n.Language)
+	addTrue("renotify", n.Renotify)
+	addTrue("requireInteraction", n.RequireInteraction)
+	addTrue("silent", n.Silent)
+	addNonEmpty("tag", n.Tag)
+	if n.Data != nil {
+		m["data"] = n.Data
+	}
+	if n.TimestampMillis != nil {
+		m["timestamp"] = *n.TimestampMillis
+	}
+	if len(n.Vibrate) > 0 {
+		m["vibrate"] = n.Vibrate
+	}
+	return m
+}
+
+func (n *WebpushNotification) MarshalJSON() ([]byte, error) {
+	m := n.standardFields()
+	for k, v := range n.CustomData {
+		if _, ok := m[k]; ok {
+			return nil, fmt.Errorf("multiple specifications for the key %q", k)
+		}
+		m[k] = v
+	}
+	return json.Marshal(m)
+}
+
+func (n *WebpushNotification) UnmarshalJSON(b []byte) error {
+	type webpushNotificationWrapper WebpushNotification
+
+	tmp := (*webpushNotificationWrapper)(n)
+	if err := json.Unmarshal(b, tmp); err != nil {
+		return err
+	}
+	allFields := make(map[string]any)
+	if err := json.Unmarshal(b, &allFields); err != nil {
+		return err
+	}
+	for _, k := range webpushNotificationKeys {
+		delete(allFields, k)
+	}
+	if len(allFields) > 0 {
+		n.CustomData = allFields
+	}
+	return nil
+}
+
+// WebpushFCMOptions contains additional options for features provided by the FCM web SDK.
+//
+// See https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages#webpushfcmoptions
+type WebpushFCMOptions struct {
+	Link           string `json:"link,omitempty"`
+	AnalyticsLabel string `json:"analytics_label,omitempty"`
+}
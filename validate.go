@@ -1,19 +1,45 @@
 package fcm
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
+// BareTopicNamePattern matches a topic name without /topics/ prefix.
+var BareTopicNamePattern = regexp.MustCompile("^[a-zA-Z0-9-_.~%]+$")
+
 var (
-	bareTopicNamePattern  = regexp.MustCompile("^[a-zA-Z0-9-_.~%]+$")
 	colorPattern          = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
 	colorWithAlphaPattern = regexp.MustCompile("^#[0-9a-fA-F]{6}([0-9a-fA-F]{2})?$")
+	locFormatPattern      = regexp.MustCompile(`%(?:(\d+)\$)?[@sd]`)
+	analyticsLabelPattern = regexp.MustCompile("^[a-zA-Z0-9-_.~%]{1,50}$")
+	webpushTopicPattern   = regexp.MustCompile("^[a-zA-Z0-9-_]{1,32}$")
 )
 
+// ValidateTopic checks a topic name with or without /topics/ prefix.
+func ValidateTopic(topic string) error {
+	if !BareTopicNamePattern.MatchString(strings.TrimPrefix(topic, "/topics/")) {
+		return errors.New("malformed topic name")
+	}
+	return nil
+}
+
+// ValidateAll validates messages without sending them.
+// Returns errors aligned with msgs, nil for a valid message.
+func ValidateAll(msgs []*Message) []error {
+	errs := make([]error, len(msgs))
+	for i, msg := range msgs {
+		errs[i] = validateMessage(msg)
+	}
+	return errs
+}
+
 func validateMessage(message *Message) error {
 	if message == nil {
 		return errors.New("message must not be nil")
@@ -25,9 +51,14 @@ func validateMessage(message *Message) error {
 	}
 
 	if message.Topic != "" {
-		bt := strings.TrimPrefix(message.Topic, "/topics/")
-		if !bareTopicNamePattern.MatchString(bt) {
-			return errors.New("malformed topic name")
+		if err := ValidateTopic(message.Topic); err != nil {
+			return err
+		}
+	}
+
+	if message.FCMOptions != nil {
+		if err := validateAnalyticsLabel(message.FCMOptions.AnalyticsLabel); err != nil {
+			return err
 		}
 	}
 
@@ -46,6 +77,116 @@ func validateMessage(message *Message) error {
 	return nil
 }
 
+func validateMessageStrict(message *Message) error {
+	if err := validateMessage(message); err != nil {
+		return err
+	}
+	if err := validateMessageCrossField(message); err != nil {
+		return err
+	}
+
+	if android := message.Android; android != nil && android.Notification != nil {
+		n := android.Notification
+		if err := validateLocArgs("titleLocKey", n.TitleLocKey, n.TitleLocArgs); err != nil {
+			return err
+		}
+		if err := validateLocArgs("bodyLocKey", n.BodyLocKey, n.BodyLocArgs); err != nil {
+			return err
+		}
+	}
+
+	if apns := message.APNS; apns != nil && apns.Payload != nil && apns.Payload.Aps != nil {
+		aps := apns.Payload.Aps
+		if aps.CriticalSound != nil && aps.CriticalSound.Critical && aps.InterruptionLevel != "critical" {
+			return errors.New("critical sound should be sent with interruption level 'critical'")
+		}
+		if alert := aps.Alert; alert != nil {
+			if err := validateLocArgs("titleLocKey", alert.TitleLocKey, alert.TitleLocArgs); err != nil {
+				return err
+			}
+			if err := validateLocArgs("subtitleLocKey", alert.SubTitleLocKey, alert.SubTitleLocArgs); err != nil {
+				return err
+			}
+			if err := validateLocArgs("locKey", alert.LocKey, alert.LocArgs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateMessageCrossField(message *Message) error {
+	if message.IsDataOnly() && (message.Android == nil || message.Android.Priority != "high") {
+		return errors.New("data-only message requires android priority 'high' to be delivered promptly")
+	}
+
+	if message.Android != nil && len(message.Android.Data) > 0 && len(message.Data) > 0 {
+		return errors.New("android data overrides message data, so message data is dropped for Android targets")
+	}
+
+	if message.Webpush != nil && len(message.Data) > 0 {
+		var keys []string
+		for k := range message.Webpush.Data {
+			if _, ok := message.Data[k]; ok {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) > 0 {
+			slices.Sort(keys)
+			return fmt.Errorf("webpush data overrides message data for keys: %q", keys)
+		}
+	}
+	return nil
+}
+
+func validateDataSize(message *Message, limit int) error {
+	size := dataSize(message.Data)
+	if message.Android != nil {
+		size += dataSize(message.Android.Data)
+	}
+	if size > limit {
+		return fmt.Errorf("data size %d bytes exceeds limit of %d bytes", size, limit)
+	}
+	return nil
+}
+
+func dataSize(data map[string]string) int {
+	size := 0
+	for k, v := range data {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// validateLocArgs checks that number of format specifiers in the key (like %@ or %1$@) matches number of args.
+// Usually the key is a resource name like GAME_PLAY_REQUEST_FORMAT without specifiers, such a key isn't checked.
+func validateLocArgs(name, key string, args []string) error {
+	if key == "" {
+		return nil
+	}
+
+	var count, maxPos int
+	for _, m := range locFormatPattern.FindAllStringSubmatch(key, -1) {
+		if m[1] == "" {
+			count++
+			continue
+		}
+		pos, err := strconv.Atoi(m[1])
+		if err != nil {
+			return fmt.Errorf("%s has malformed format specifier: %q", name, m[0])
+		}
+		maxPos = max(maxPos, pos)
+	}
+	if count == 0 && maxPos == 0 {
+		return nil
+	}
+
+	if want := max(count, maxPos); want != len(args) {
+		return fmt.Errorf("%s has %d format specifiers but %d args given", name, want, len(args))
+	}
+	return nil
+}
+
 func validateNotification(notification *Notification) error {
 	if notification == nil {
 		return nil
@@ -70,9 +211,16 @@ func validateAndroidConfig(config *AndroidConfig) error {
 	case config.Priority != "" && config.Priority != "normal" && config.Priority != "high":
 		return errors.New("priority must be 'normal' or 'high'")
 
-	default:
-		return validateAndroidNotification(config.Notification)
+	case config.DirectBootOK && config.Priority != "high":
+		return errors.New("direct_boot_ok requires priority 'high'")
+	}
+
+	if config.FCMOptions != nil {
+		if err := validateAnalyticsLabel(config.FCMOptions.AnalyticsLabel); err != nil {
+			return err
+		}
 	}
+	return validateAndroidNotification(config.Notification)
 }
 
 func validateAndroidNotification(notification *AndroidNotification) error {
@@ -88,6 +236,19 @@ func validateAndroidNotification(notification *AndroidNotification) error {
 
 	case len(notification.BodyLocArgs) > 0 && notification.BodyLocKey == "":
 		return errors.New("bodyLocKey is required when specifying bodyLocArgs")
+
+	case notification.NotificationCount != nil && *notification.NotificationCount < 0:
+		return errors.New("notificationCount must not be negative")
+
+	case notification.DefaultSound && notification.Sound != "":
+		return errors.New("sound must be empty when defaultSound is true, default sound would be played")
+
+	case notification.PublicVersion != nil && notification.Visibility != VisibilityPrivate:
+		return errors.New("publicVersion requires private visibility")
+	}
+
+	if err := validateAndroidNotification(notification.PublicVersion); err != nil {
+		return fmt.Errorf("publicVersion: %w", err)
 	}
 
 	if image := notification.ImageURL; image != "" {
@@ -119,6 +280,15 @@ func validateLightSettings(light *LightSettings) error {
 	case light.LightOffDurationMillis < 0:
 		return errors.New("lightOffDuration must not be negative")
 
+	case light.LightOnDurationMillis == 0 && light.LightOffDurationMillis == 0:
+		return errors.New("lightOnDuration or lightOffDuration must be set, LED would never blink")
+
+	case light.LightOnDurationMillis > MaxLEDDurationMillis:
+		return fmt.Errorf("lightOnDuration must not exceed %d ms, got %d", MaxLEDDurationMillis, light.LightOnDurationMillis)
+
+	case light.LightOffDurationMillis > MaxLEDDurationMillis:
+		return fmt.Errorf("lightOffDuration must not exceed %d ms, got %d", MaxLEDDurationMillis, light.LightOffDurationMillis)
+
 	default:
 		return nil
 	}
@@ -129,6 +299,10 @@ func validateAPNSConfig(config *APNSConfig) error {
 		return nil
 	}
 
+	if err := validateAPNSHeaders(config.Headers); err != nil {
+		return err
+	}
+
 	if config.FCMOptions != nil {
 		image := config.FCMOptions.ImageURL
 		if image != "" {
@@ -136,8 +310,58 @@ func validateAPNSConfig(config *APNSConfig) error {
 				return fmt.Errorf("invalid image URL: %q", image)
 			}
 		}
+		if err := validateAnalyticsLabel(config.FCMOptions.AnalyticsLabel); err != nil {
+			return err
+		}
+	}
+	if err := validateAPNSPayload(config.Payload); err != nil {
+		return err
+	}
+	return validateAPNSPayloadSize(config)
+}
+
+// APNS payload limits, VoIP notifications are allowed to be larger.
+const (
+	maxAPNSPayloadSize     = 4096
+	maxAPNSVoIPPayloadSize = 5120
+)
+
+func validateAPNSPayloadSize(config *APNSConfig) error {
+	if config.Payload == nil {
+		return nil
+	}
+
+	limit := maxAPNSPayloadSize
+	for k, v := range config.Headers {
+		if strings.EqualFold(k, "apns-push-type") && v == "voip" {
+			limit = maxAPNSVoIPPayloadSize
+		}
+	}
+
+	b, err := json.Marshal(config.Payload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal APNS payload: %w", err)
+	}
+	if len(b) > limit {
+		return fmt.Errorf("APNS payload is %d bytes, at most %d bytes are allowed", len(b), limit)
+	}
+	return nil
+}
+
+func validateAPNSHeaders(headers map[string]string) error {
+	for k, v := range headers {
+		switch strings.ToLower(k) {
+		case "apns-priority":
+			if v != "1" && v != "5" && v != "10" {
+				return fmt.Errorf("apns-priority header must be '1', '5' or '10', got %q", v)
+			}
+		case "apns-expiration":
+			if n, err := strconv.ParseInt(v, 10, 64); err != nil || n < 0 {
+				return fmt.Errorf("apns-expiration header must be a non-negative Unix timestamp, got %q", v)
+			}
+		}
 	}
-	return validateAPNSPayload(config.Payload)
+	return nil
 }
 
 func validateAPNSPayload(payload *APNSPayload) error {
@@ -145,11 +369,8 @@ func validateAPNSPayload(payload *APNSPayload) error {
 		return nil
 	}
 
-	m := payload.standardFields()
-	for k := range payload.CustomData {
-		if _, contains := m[k]; contains {
-			return fmt.Errorf("multiple specifications for the key %q", k)
-		}
+	if _, ok := payload.CustomData["aps"]; ok {
+		return fmt.Errorf("multiple specifications for the key %q", "aps")
 	}
 	return validateAps(payload.Aps)
 }
@@ -158,28 +379,70 @@ func validateAps(aps *Aps) error {
 	if aps == nil {
 		return nil
 	}
-	if aps.Alert != nil && aps.AlertString != "" {
+	if countTrue(aps.Alert != nil, aps.AlertText != nil, aps.AlertString != "") > 1 {
 		return errors.New("multiple alert specifications")
 	}
+	if aps.Badge != nil && *aps.Badge < 0 {
+		return errors.New("badge must not be negative")
+	}
 
 	if aps.CriticalSound != nil {
 		if aps.Sound != "" {
 			return errors.New("multiple sound specifications")
 		}
-		if aps.CriticalSound.Volume < 0 || aps.CriticalSound.Volume > 1 {
-			return errors.New("critical sound volume must be in the interval [0, 1]")
+		if v := aps.CriticalSound.Volume; v != nil {
+			if err := validateCriticalSoundVolume(*v); err != nil {
+				return err
+			}
+		}
+		if aps.CriticalSound.Critical && aps.CriticalSound.Name == "" {
+			return errors.New("critical sound requires a sound name")
+		}
+	}
+
+	hasSound := aps.Sound != "" || aps.CriticalSound != nil
+	if hasSound && aps.isContentAvailable() && !aps.hasAlert() {
+		return errors.New("sound must not be set for a background notification with content-available and no alert")
+	}
+
+	switch aps.InterruptionLevel {
+	case "", "passive", "active", "time-sensitive", "critical":
+	default:
+		return errors.New("interruption level must be 'passive', 'active', 'time-sensitive' or 'critical'")
+	}
+	if aps.InterruptionLevel == "critical" && (aps.CriticalSound == nil || !aps.CriticalSound.Critical) {
+		return errors.New("interruption level 'critical' requires a critical sound")
+	}
+	if aps.ContentState != nil && aps.InterruptionLevel == "passive" {
+		return errors.New("content state requires interruption level other than 'passive'")
+	}
+
+	// Safari push notifications require an alert with title and body and don't support iOS-only flags.
+	if len(aps.URLArguments) > 0 {
+		switch {
+		case aps.Alert == nil || aps.Alert.Title == "" || aps.Alert.Body == "":
+			return errors.New("url arguments require an alert with title and body for Safari push notifications")
+		case aps.isContentAvailable(), aps.MutableContent, aps.MutableContentExplicit != nil:
+			return errors.New("url arguments must not be combined with content-available or mutable-content")
 		}
 	}
 
-	m := aps.standardFields()
 	for k := range aps.CustomData {
-		if _, contains := m[k]; contains {
+		if slices.Contains(apsKeys, k) {
 			return fmt.Errorf("multiple specifications for the key: %q", k)
 		}
 	}
 	return validateApsAlert(aps.Alert)
 }
 
+func validateCriticalSoundVolume(v float64) error {
+	// Negated form also rejects NaN.
+	if !(v >= 0 && v <= 1) {
+		return errors.New("critical sound volume must be in the interval [0, 1]")
+	}
+	return nil
+}
+
 func validateApsAlert(alert *ApsAlert) error {
 	switch {
 	case alert == nil:
@@ -194,45 +457,126 @@ func validateApsAlert(alert *ApsAlert) error {
 	case len(alert.LocArgs) > 0 && alert.LocKey == "":
 		return errors.New("locKey is required when specifying locArgs")
 
+	case alert.LaunchImage != "" && alert.LaunchImageURL != "":
+		return errors.New("launchImage and launchImageURL must not be set together")
+
 	default:
 		return nil
 	}
 }
 
 func validateWebpushConfig(webpush *WebpushConfig) error {
-	if webpush == nil || webpush.Notification == nil {
+	if webpush == nil {
+		return nil
+	}
+	if err := validateWebpushHeaders(webpush.Headers); err != nil {
+		return err
+	}
+	if err := validateWebpushNotification(webpush.Notification); err != nil {
+		return err
+	}
+
+	if webpush.FCMOptions != nil {
+		if link := webpush.FCMOptions.Link; link != "" {
+			// url.Parse instead of url.ParseRequestURI to handle a fragment of a deep link.
+			p, err := url.Parse(link)
+			switch {
+			case err != nil, p.Host == "":
+				return fmt.Errorf("invalid link URL: %q", link)
+			case p.Scheme != "https":
+				return fmt.Errorf("invalid link URL: %q; want scheme: %q", link, "https")
+			}
+		}
+		if err := validateAnalyticsLabel(webpush.FCMOptions.AnalyticsLabel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateWebpushHeaders(headers map[string]string) error {
+	for k, v := range headers {
+		switch strings.ToLower(k) {
+		case "urgency":
+			if !WebpushUrgency(v).isValid() {
+				return fmt.Errorf("urgency header must be 'very-low', 'low', 'normal' or 'high', got %q", v)
+			}
+		case "ttl":
+			if n, err := strconv.ParseInt(v, 10, 64); err != nil || n < 0 {
+				return fmt.Errorf("ttl header must be a non-negative number of seconds, got %q", v)
+			}
+		case "topic":
+			if !webpushTopicPattern.MatchString(v) {
+				return fmt.Errorf("topic header must be 1 to 32 alphanumeric, '-' or '_' characters, got %q", v)
+			}
+		case "content-encoding":
+			if v != "aes128gcm" && v != "aesgcm" {
+				return fmt.Errorf("content-encoding header must be 'aes128gcm' or 'aesgcm', got %q", v)
+			}
+		case "authorization":
+			return errors.New("authorization header must not be set, it's set by FCM")
+		}
+	}
+	return nil
+}
+
+// maxWebpushActions is a limit of actions displayed by Chrome.
+const maxWebpushActions = 2
+
+func validateWebpushNotification(notification *WebpushNotification) error {
+	if notification == nil {
 		return nil
 	}
 
-	dir := webpush.Notification.Direction
+	dir := notification.Direction
 	if dir != "" && dir != "ltr" && dir != "rtl" && dir != "auto" {
 		return errors.New("direction must be 'ltr', 'rtl' or 'auto'")
 	}
 
-	m := webpush.Notification.standardFields()
-	for k := range webpush.Notification.CustomData {
-		if _, contains := m[k]; contains {
-			return fmt.Errorf("multiple specifications for the key %q", k)
+	if len(notification.Actions) > maxWebpushActions {
+		return fmt.Errorf("at most %d actions are allowed, browsers drop the rest", maxWebpushActions)
+	}
+	for i, action := range notification.Actions {
+		switch {
+		case action == nil:
+			return fmt.Errorf("action %d must not be nil", i)
+		case action.Action == "":
+			return fmt.Errorf("action %d must have non-empty action", i)
+		case action.Icon != "" && isValidURL(action.Icon) != nil:
+			return fmt.Errorf("action %d has invalid icon URL: %q", i, action.Icon)
 		}
 	}
 
-	if webpush.FCMOptions != nil {
-		link := webpush.FCMOptions.Link
-		p, err := url.ParseRequestURI(link)
-		if err != nil {
-			return fmt.Errorf("invalid link URL: %q", link)
-		} else if p.Scheme != "https" {
-			return fmt.Errorf("invalid link URL: %q; want scheme: %q", link, "https")
+	for k := range notification.CustomData {
+		if slices.Contains(webpushNotificationKeys, k) {
+			return fmt.Errorf("multiple specifications for the key %q", k)
 		}
 	}
 	return nil
 }
 
+func validateAnalyticsLabel(label string) error {
+	if label != "" && !analyticsLabelPattern.MatchString(label) {
+		return fmt.Errorf("malformed analytics label: %q", label)
+	}
+	return nil
+}
+
 func isValidURL(link string) error {
 	_, err := url.ParseRequestURI(link)
 	return err
 }
 
+func countTrue(bs ...bool) int {
+	count := 0
+	for _, b := range bs {
+		if b {
+			count++
+		}
+	}
+	return count
+}
+
 func countNonEmpty(ss ...string) int {
 	count := 0
 	for _, s := range ss {
@@ -0,0 +1,644 @@
+package fcm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateStrictLocArgs(t *testing.T) {
+	testCases := []struct {
+		alert   *ApsAlert
+		wantErr bool
+	}{
+		{&ApsAlert{TitleLocKey: "Hello %@", TitleLocArgs: []string{"Bob"}}, false},
+		{&ApsAlert{TitleLocKey: "Hello %@ and %@", TitleLocArgs: []string{"Bob"}}, true},
+		{&ApsAlert{TitleLocKey: "%2$@ then %1$@", TitleLocArgs: []string{"a", "b"}}, false},
+		{&ApsAlert{TitleLocKey: "%2$@ then %1$@", TitleLocArgs: []string{"a"}}, true},
+		{&ApsAlert{SubTitleLocKey: "no args", SubTitleLocArgs: []string{"a"}}, false},
+		{&ApsAlert{LocKey: "GAME_PLAY_REQUEST_FORMAT", LocArgs: []string{"Jenna", "Frank"}}, false},
+		{&ApsAlert{LocKey: "%@ says %@", LocArgs: []string{"a", "b"}}, false},
+		{&ApsAlert{LocKey: "%@ says %@", LocArgs: []string{"a", "b", "c"}}, true},
+	}
+
+	for _, tc := range testCases {
+		msg := Message{
+			Token: "token",
+			APNS: &APNSConfig{
+				Payload: &APNSPayload{Aps: &Aps{Alert: tc.alert}},
+			},
+		}
+		mustOk(t, msg.IsValid())
+
+		err := msg.ValidateStrict()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%+v: want error %v, got %v", tc.alert, tc.wantErr, err)
+		}
+	}
+}
+
+func TestValidateStrictAndroidLocArgs(t *testing.T) {
+	msg := Message{
+		Token: "token",
+		Android: &AndroidConfig{
+			Notification: &AndroidNotification{
+				BodyLocKey:  "%1$s sent %2$d photos",
+				BodyLocArgs: []string{"Alice"},
+			},
+		},
+	}
+	mustOk(t, msg.IsValid())
+	mustFail(t, msg.ValidateStrict())
+
+	msg.Android.Notification.BodyLocArgs = append(msg.Android.Notification.BodyLocArgs, "2")
+	mustOk(t, msg.ValidateStrict())
+
+	msg.Android.Notification.BodyLocKey = "photos_sent"
+	mustOk(t, msg.ValidateStrict())
+}
+
+func TestValidateStrictWebpushData(t *testing.T) {
+	msg := Message{
+		Token: "token",
+		Data:  map[string]string{"a": "1", "b": "2"},
+		Notification: &Notification{
+			Title: "Hello",
+		},
+		Webpush: &WebpushConfig{
+			Data: map[string]string{"b": "3", "c": "4"},
+		},
+	}
+	mustOk(t, msg.IsValid())
+
+	err := msg.ValidateStrict()
+	mustFail(t, err)
+	mustEqual(t, err.Error(), `webpush data overrides message data for keys: ["b"]`)
+
+	delete(msg.Webpush.Data, "b")
+	mustOk(t, msg.ValidateStrict())
+}
+
+func TestValidateStrictDataOnly(t *testing.T) {
+	testCases := []struct {
+		msg        Message
+		isDataOnly bool
+		wantErr    bool
+	}{
+		{
+			msg:        Message{Token: "token", Data: map[string]string{"k": "v"}},
+			isDataOnly: true,
+			wantErr:    true,
+		},
+		{
+			msg: Message{
+				Token:   "token",
+				Data:    map[string]string{"k": "v"},
+				Android: &AndroidConfig{Priority: "normal"},
+			},
+			isDataOnly: true,
+			wantErr:    true,
+		},
+		{
+			msg: Message{
+				Token:   "token",
+				Data:    map[string]string{"k": "v"},
+				Android: &AndroidConfig{Priority: "high"},
+			},
+			isDataOnly: true,
+			wantErr:    false,
+		},
+		{
+			msg: Message{
+				Token:        "token",
+				Data:         map[string]string{"k": "v"},
+				Notification: &Notification{Title: "Hello"},
+			},
+			isDataOnly: false,
+			wantErr:    false,
+		},
+		{
+			msg: Message{
+				Token: "token",
+				Data:  map[string]string{"k": "v"},
+				APNS:  &APNSConfig{Payload: &APNSPayload{Aps: &Aps{AlertString: "Hello"}}},
+			},
+			isDataOnly: false,
+			wantErr:    false,
+		},
+		{
+			msg:        Message{Token: "token"},
+			isDataOnly: false,
+			wantErr:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		mustEqual(t, tc.msg.IsDataOnly(), tc.isDataOnly)
+		mustOk(t, tc.msg.IsValid())
+
+		err := tc.msg.ValidateStrict()
+		mustEqual(t, err != nil, tc.wantErr)
+	}
+}
+
+func TestValidateApsSound(t *testing.T) {
+	testCases := []struct {
+		aps     *Aps
+		wantErr string
+	}{
+		{&Aps{Sound: "default"}, ""},
+		{&Aps{ContentAvailable: true}, ""},
+		{&Aps{ContentAvailable: true, Sound: "default", AlertString: "Hello"}, ""},
+		{&Aps{CriticalSound: &CriticalSound{Critical: true, Name: "alarm"}}, ""},
+		{
+			&Aps{CriticalSound: &CriticalSound{Critical: true}},
+			"critical sound requires a sound name",
+		},
+		{
+			&Aps{ContentAvailable: true, Sound: "default"},
+			"sound must not be set for a background notification with content-available and no alert",
+		},
+		{
+			&Aps{ContentAvailableExplicit: ptr(true), CriticalSound: &CriticalSound{Name: "alarm"}},
+			"sound must not be set for a background notification with content-available and no alert",
+		},
+	}
+
+	for _, tc := range testCases {
+		msg := Message{Token: "token", APNS: &APNSConfig{Payload: &APNSPayload{Aps: tc.aps}}}
+		err := msg.IsValid()
+		if tc.wantErr == "" {
+			mustOk(t, err)
+			continue
+		}
+		mustFail(t, err)
+		mustEqual(t, err.Error(), tc.wantErr)
+	}
+}
+
+func TestValidateTopic(t *testing.T) {
+	testCases := []struct {
+		topic   string
+		wantErr bool
+	}{
+		{"news", false},
+		{"/topics/news", false},
+		{"news-2024_v1.~%", false},
+		{"", true},
+		{"/topics/", true},
+		{"bad topic", true},
+		{"news/sport", true},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateTopic(tc.topic)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%q: want error %v, got %v", tc.topic, tc.wantErr, err)
+		}
+	}
+}
+
+func TestValidateStrictAndroidData(t *testing.T) {
+	testCases := []struct {
+		data        DataMap
+		androidData DataMap
+		want        DataMap
+		wantErr     bool
+	}{
+		{DataMap{"a": "1"}, nil, DataMap{"a": "1"}, false},
+		{nil, DataMap{"b": "2"}, DataMap{"b": "2"}, false},
+		{DataMap{"a": "1"}, DataMap{"b": "2"}, DataMap{"b": "2"}, true},
+	}
+
+	for _, tc := range testCases {
+		msg := Message{
+			Token:        "token",
+			Data:         tc.data,
+			Notification: &Notification{Title: "Hello"},
+			Android:      &AndroidConfig{Data: tc.androidData},
+		}
+		mustOk(t, msg.IsValid())
+		mustEqual(t, msg.AndroidData(), tc.want)
+
+		err := msg.ValidateStrict()
+		mustEqual(t, err != nil, tc.wantErr)
+	}
+}
+
+func TestValidateWebpushNotificationReservedKeys(t *testing.T) {
+	testCases := []struct {
+		notification *WebpushNotification
+		wantErr      bool
+	}{
+		{&WebpushNotification{CustomData: map[string]any{"extra": 1}}, false},
+		{&WebpushNotification{Title: "Hello", CustomData: map[string]any{"title": "Hi"}}, true},
+		{&WebpushNotification{CustomData: map[string]any{"title": "Hi"}}, true},
+		{&WebpushNotification{CustomData: map[string]any{"requireInteraction": true}}, true},
+		{&WebpushNotification{CustomData: map[string]any{"data": "custom"}}, true},
+	}
+
+	for _, tc := range testCases {
+		msg := Message{Token: "token", Webpush: &WebpushConfig{Notification: tc.notification}}
+		err := msg.IsValid()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%+v: want error %v, got %v", tc.notification.CustomData, tc.wantErr, err)
+		}
+	}
+}
+
+func TestValidateWebpushActions(t *testing.T) {
+	reply := &WebpushNotificationAction{Action: "reply", Title: "Reply", Icon: "https://example.com/reply.png"}
+	dismiss := &WebpushNotificationAction{Action: "dismiss", Title: "Dismiss"}
+
+	testCases := []struct {
+		actions []*WebpushNotificationAction
+		wantErr bool
+	}{
+		{nil, false},
+		{[]*WebpushNotificationAction{reply}, false},
+		{[]*WebpushNotificationAction{reply, dismiss}, false},
+		{[]*WebpushNotificationAction{reply, dismiss, reply}, true},
+		{[]*WebpushNotificationAction{{Title: "No action"}}, true},
+		{[]*WebpushNotificationAction{{Action: "open", Icon: "icon.png"}}, true},
+		{[]*WebpushNotificationAction{nil}, true},
+	}
+
+	for i, tc := range testCases {
+		msg := Message{
+			Token:   "token",
+			Webpush: &WebpushConfig{Notification: &WebpushNotification{Actions: tc.actions}},
+		}
+		err := msg.IsValid()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("case %d: want error %v, got %v", i, tc.wantErr, err)
+		}
+	}
+}
+
+func TestValidateApsReservedKeys(t *testing.T) {
+	testCases := []struct {
+		payload *APNSPayload
+		wantErr bool
+	}{
+		{&APNSPayload{Aps: &Aps{CustomData: map[string]any{"extra": 1}}}, false},
+		{&APNSPayload{Aps: &Aps{CustomData: map[string]any{"badge": 1}}}, true},
+		{&APNSPayload{Aps: &Aps{CustomData: map[string]any{"content-available": 1}}}, true},
+		{&APNSPayload{Aps: &Aps{CustomData: map[string]any{"thread-id": "t"}}}, true},
+		{&APNSPayload{Aps: &Aps{Badge: ptr(1), CustomData: map[string]any{"badge": 2}}}, true},
+		{&APNSPayload{CustomData: map[string]any{"extra": 1}}, false},
+		{&APNSPayload{CustomData: map[string]any{"aps": map[string]any{}}}, true},
+	}
+
+	for i, tc := range testCases {
+		msg := Message{Token: "token", APNS: &APNSConfig{Payload: tc.payload}}
+		err := msg.IsValid()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("case %d: want error %v, got %v", i, tc.wantErr, err)
+		}
+	}
+}
+
+func TestValidateLightSettingsMaxDuration(t *testing.T) {
+	testCases := []struct {
+		millis  int64
+		wantErr bool
+	}{
+		{59999, false},
+		{60000, false},
+		{60001, true},
+	}
+
+	for _, tc := range testCases {
+		for _, light := range []*LightSettings{
+			{Color: "#FF0000", LightOnDurationMillis: tc.millis},
+			{Color: "#FF0000", LightOffDurationMillis: tc.millis},
+		} {
+			msg := Message{
+				Token:   "token",
+				Android: &AndroidConfig{Notification: &AndroidNotification{LightSettings: light}},
+			}
+			err := msg.IsValid()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("%+v: want error %v, got %v", light, tc.wantErr, err)
+			}
+		}
+	}
+}
+
+func TestValidateApsURLArguments(t *testing.T) {
+	alert := &ApsAlert{Title: "Flight A998 now boarding", Body: "Boarding has begun"}
+
+	testCases := []struct {
+		aps     *Aps
+		wantErr bool
+	}{
+		{&Aps{Alert: alert, URLArguments: []string{"boarding", "A998"}}, false},
+		{&Aps{URLArguments: []string{"boarding"}}, true},
+		{&Aps{Alert: &ApsAlert{Title: "Title"}, URLArguments: []string{"boarding"}}, true},
+		{&Aps{Alert: alert, ContentAvailable: true, URLArguments: []string{"boarding"}}, true},
+		{&Aps{Alert: alert, MutableContent: true, URLArguments: []string{"boarding"}}, true},
+	}
+
+	for i, tc := range testCases {
+		msg := Message{Token: "token", APNS: &APNSConfig{Payload: &APNSPayload{Aps: tc.aps}}}
+		err := msg.IsValid()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("case %d: want error %v, got %v", i, tc.wantErr, err)
+		}
+	}
+
+	aps := &Aps{Alert: alert, URLArguments: []string{"boarding", "A998"}}
+	testRoundTrip(t, aps)
+	b, err := json.Marshal(aps)
+	mustOk(t, err)
+	mustEqual(t, string(b), `{"alert":{"title":"Flight A998 now boarding","body":"Boarding has begun"},"url-args":["boarding","A998"]}`)
+}
+
+func TestValidateAndroidSound(t *testing.T) {
+	testCases := []struct {
+		notification *AndroidNotification
+		wantErr      bool
+	}{
+		{&AndroidNotification{Sound: "ding.mp3"}, false},
+		{&AndroidNotification{DefaultSound: true}, false},
+		{&AndroidNotification{Sound: "ding.mp3", DefaultSound: true}, true},
+	}
+
+	for _, tc := range testCases {
+		msg := Message{Token: "token", Android: &AndroidConfig{Notification: tc.notification}}
+		err := msg.IsValid()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%+v: want error %v, got %v", tc.notification, tc.wantErr, err)
+		}
+	}
+}
+
+func TestValidateApsAlertLaunchImage(t *testing.T) {
+	testCases := []struct {
+		alert   *ApsAlert
+		wantErr bool
+	}{
+		{&ApsAlert{LaunchImage: "launch.png"}, false},
+		{&ApsAlert{LaunchImageURL: "https://example.com/launch.png"}, false},
+		{&ApsAlert{LaunchImage: "launch.png", LaunchImageURL: "https://example.com/launch.png"}, true},
+	}
+
+	for _, tc := range testCases {
+		msg := Message{Token: "token", APNS: &APNSConfig{Payload: &APNSPayload{Aps: &Aps{Alert: tc.alert}}}}
+		err := msg.IsValid()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%+v: want error %v, got %v", tc.alert, tc.wantErr, err)
+		}
+	}
+}
+
+func TestValidateAndroidDirectBootOK(t *testing.T) {
+	msg := Message{Token: "token", Android: &AndroidConfig{Priority: "high", DirectBootOK: true}}
+	mustOk(t, msg.IsValid())
+
+	msg.Android.Priority = "normal"
+	err := msg.IsValid()
+	mustFail(t, err)
+	mustEqual(t, err.Error(), "direct_boot_ok requires priority 'high'")
+}
+
+func TestValidateAnalyticsLabelAllLevels(t *testing.T) {
+	withLabel := func(label string) []Message {
+		return []Message{
+			{Token: "token", FCMOptions: &FCMOptions{AnalyticsLabel: label}},
+			{Token: "token", Android: &AndroidConfig{FCMOptions: &AndroidFCMOptions{AnalyticsLabel: label}}},
+			{Token: "token", APNS: &APNSConfig{FCMOptions: &APNSFCMOptions{AnalyticsLabel: label}}},
+			{Token: "token", Webpush: &WebpushConfig{FCMOptions: &WebpushFCMOptions{AnalyticsLabel: label}}},
+		}
+	}
+
+	for _, msg := range withLabel("campaign_1.v2~%") {
+		mustOk(t, msg.IsValid())
+	}
+	for _, label := range []string{"no spaces", "a/b", "123456789012345678901234567890123456789012345678901"} {
+		for _, msg := range withLabel(label) {
+			mustFail(t, msg.IsValid())
+		}
+	}
+}
+
+func TestValidateWebpushHeaders(t *testing.T) {
+	testCases := []struct {
+		headers map[string]string
+		wantErr string
+	}{
+		{map[string]string{"Content-Encoding": "aes128gcm"}, ""},
+		{map[string]string{"content-encoding": "aesgcm"}, ""},
+		{
+			map[string]string{"Content-Encoding": "gzip"},
+			`content-encoding header must be 'aes128gcm' or 'aesgcm', got "gzip"`,
+		},
+		{
+			map[string]string{"Authorization": "vapid t=token"},
+			"authorization header must not be set, it's set by FCM",
+		},
+	}
+
+	for _, tc := range testCases {
+		msg := Message{Token: "token", Webpush: &WebpushConfig{Headers: tc.headers}}
+		err := msg.IsValid()
+		if tc.wantErr == "" {
+			mustOk(t, err)
+			continue
+		}
+		mustFail(t, err)
+		mustEqual(t, err.Error(), tc.wantErr)
+	}
+}
+
+func TestValidateWebpushLink(t *testing.T) {
+	testCases := []struct {
+		link    string
+		wantErr bool
+	}{
+		{"https://example.com", false},
+		{"https://example.com/promo?utm_source=push&utm_medium=web", false},
+		{"https://example.com/app#/inbox/42", false},
+		{"https://example.com/app?tab=inbox#message-42", false},
+		{"http://example.com", true},
+		{"/relative/path", true},
+		{"https://", true},
+		{"https://exa mple.com", true},
+	}
+
+	for _, tc := range testCases {
+		msg := Message{Token: "token", Webpush: &WebpushConfig{FCMOptions: &WebpushFCMOptions{Link: tc.link}}}
+		err := msg.IsValid()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%q: want error %v, got %v", tc.link, tc.wantErr, err)
+		}
+	}
+}
+
+func TestValidateAndroidNotificationCount(t *testing.T) {
+	n := &AndroidNotification{}
+	msg := Message{Token: "token", Android: &AndroidConfig{Notification: n}}
+
+	n.SetNotificationCount(3)
+	mustEqual(t, n.NotificationCount, ptr(3))
+	mustOk(t, msg.IsValid())
+
+	n.SetNotificationCount(0)
+	mustOk(t, msg.IsValid())
+
+	n.SetNotificationCount(-1)
+	mustFail(t, msg.IsValid())
+}
+
+func TestValidateLightSettingsDegenerate(t *testing.T) {
+	testCases := []struct {
+		light   *LightSettings
+		wantErr bool
+	}{
+		{&LightSettings{Color: "#FF0000", LightOnDurationMillis: 100, LightOffDurationMillis: 200}, false},
+		{&LightSettings{Color: "#FF0000", LightOnDurationMillis: 100}, false},
+		{&LightSettings{Color: "#FF0000"}, true},
+		{&LightSettings{LightOnDurationMillis: 100}, true},
+		{&LightSettings{}, true},
+	}
+
+	for _, tc := range testCases {
+		msg := Message{
+			Token:   "token",
+			Android: &AndroidConfig{Notification: &AndroidNotification{LightSettings: tc.light}},
+		}
+		err := msg.IsValid()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%+v: want error %v, got %v", tc.light, tc.wantErr, err)
+		}
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	msgs := []*Message{
+		{Token: "token"},
+		{Token: "token", Topic: "news"},
+		nil,
+		{Topic: "news", Android: &AndroidConfig{Priority: "urgent"}},
+		{Condition: "'a' in topics"},
+	}
+
+	errs := ValidateAll(msgs)
+	mustEqual(t, len(errs), len(msgs))
+	for i, wantErr := range []bool{false, true, true, true, false} {
+		if (errs[i] != nil) != wantErr {
+			t.Fatalf("message %d: want error %v, got %v", i, wantErr, errs[i])
+		}
+	}
+
+	mustEqual(t, len(ValidateAll(nil)), 0)
+}
+
+func TestValidateApsCriticalInterruptionLevel(t *testing.T) {
+	critical := &CriticalSound{Critical: true, Name: "alarm.aiff"}
+
+	testCases := []struct {
+		aps           *Aps
+		wantErr       bool
+		wantStrictErr bool
+	}{
+		{&Aps{InterruptionLevel: "critical", CriticalSound: critical}, false, false},
+		{&Aps{InterruptionLevel: "critical"}, true, true},
+		{&Aps{InterruptionLevel: "critical", CriticalSound: &CriticalSound{Name: "alarm.aiff"}}, true, true},
+		{&Aps{InterruptionLevel: "critical", Sound: "default"}, true, true},
+		{&Aps{CriticalSound: critical}, false, true},
+		{&Aps{InterruptionLevel: "active", CriticalSound: critical}, false, true},
+		{&Aps{CriticalSound: &CriticalSound{Name: "ding.aiff"}}, false, false},
+	}
+
+	for i, tc := range testCases {
+		msg := Message{Token: "token", APNS: &APNSConfig{Payload: &APNSPayload{Aps: tc.aps}}}
+		if err := msg.IsValid(); (err != nil) != tc.wantErr {
+			t.Fatalf("case %d: want error %v, got %v", i, tc.wantErr, err)
+		}
+		if err := msg.ValidateStrict(); (err != nil) != tc.wantStrictErr {
+			t.Fatalf("case %d: want strict error %v, got %v", i, tc.wantStrictErr, err)
+		}
+	}
+}
+
+func TestValidateAPNSPayloadSize(t *testing.T) {
+	// payload returns a payload marshaled to exactly size bytes.
+	payload := func(size int) *APNSPayload {
+		p := &APNSPayload{Aps: &Aps{AlertString: "Hello"}, CustomData: map[string]any{"k": ""}}
+		b, err := json.Marshal(p)
+		mustOk(t, err)
+		p.CustomData["k"] = strings.Repeat("x", size-len(b))
+		return p
+	}
+
+	testCases := []struct {
+		pushType string
+		size     int
+		wantErr  bool
+	}{
+		{"alert", 4096, false},
+		{"alert", 4097, true},
+		{"", 4097, true},
+		{"voip", 5120, false},
+		{"voip", 5121, true},
+	}
+
+	for _, tc := range testCases {
+		config := &APNSConfig{Payload: payload(tc.size)}
+		if tc.pushType != "" {
+			config.Headers = map[string]string{"apns-push-type": tc.pushType}
+		}
+		msg := Message{Token: "token", APNS: config}
+		if err := msg.IsValid(); (err != nil) != tc.wantErr {
+			t.Fatalf("push type %q, size %d: want error %v, got %v", tc.pushType, tc.size, tc.wantErr, err)
+		}
+	}
+}
+
+func TestReservedKeysMatchStandardFields(t *testing.T) {
+	webpush := &WebpushNotification{
+		Actions:            []*WebpushNotificationAction{{Action: "a"}},
+		Title:              "t",
+		Body:               "b",
+		Icon:               "i",
+		Badge:              "b",
+		Direction:          "ltr",
+		Data:               "d",
+		Image:              "i",
+		Language:           "en",
+		Renotify:           true,
+		RequireInteraction: true,
+		Silent:             true,
+		Tag:                "t",
+		TimestampMillis:    ptr(int64(1)),
+		Vibrate:            []int{1},
+	}
+	fields := webpush.standardFields()
+	mustEqual(t, len(fields), len(webpushNotificationKeys))
+	for _, k := range webpushNotificationKeys {
+		if _, ok := fields[k]; !ok {
+			t.Fatalf("webpush key %q is not a standard field", k)
+		}
+	}
+
+	aps := &Aps{
+		AlertString:       "a",
+		Badge:             ptr(1),
+		Sound:             "s",
+		ContentAvailable:  true,
+		MutableContent:    true,
+		Category:          "c",
+		ThreadID:          "t",
+		InterruptionLevel: "active",
+		ContentState:      map[string]any{"k": "v"},
+		URLArguments:      []string{"u"},
+	}
+	fields = aps.standardFields()
+	mustEqual(t, len(fields), len(apsKeys))
+	for _, k := range apsKeys {
+		if _, ok := fields[k]; !ok {
+			t.Fatalf("aps key %q is not a standard field", k)
+		}
+	}
+}